@@ -0,0 +1,181 @@
+package application
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/obnahsgnaw/application/pkg/debug"
+	"github.com/obnahsgnaw/application/service/regCenter"
+	"go.uber.org/multierr"
+	"go.uber.org/zap"
+	"net"
+	"net/http"
+	"net/http/pprof"
+	"sort"
+)
+
+type adminDebug struct {
+	Debug      bool `json:"debug"`
+	Overridden bool `json:"overridden"`
+}
+
+// AdminHandler return the admin http handler: health, readiness, servers, cluster, registry, log level, debug, metrics and pprof
+func (app *Application) AdminHandler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/health", app.adminHealth(false))
+	mux.HandleFunc("/ready", app.adminHealth(true))
+	mux.HandleFunc("/servers", app.adminServers)
+	mux.HandleFunc("/cluster", app.adminCluster)
+	mux.HandleFunc("/registry", app.adminRegistry)
+	mux.HandleFunc("/log-level", app.adminLogLevel(false))
+	mux.HandleFunc("/log-level/trace", app.adminLogLevel(true))
+	mux.HandleFunc("/debug", app.adminDebug)
+	mux.Handle("/metrics", app.metrics.Handler())
+	mux.HandleFunc("/debug/pprof/", pprof.Index)
+	mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+	return mux
+}
+
+func (app *Application) startAdmin() error {
+	if app.adminAddr == "" {
+		return nil
+	}
+	l, err := net.Listen("tcp", app.adminAddr)
+	if err != nil {
+		return err
+	}
+	srv := &http.Server{Handler: app.AdminHandler()}
+	app.admin = srv
+	app.adminListener = l
+	go func() {
+		if err1 := srv.Serve(l); err1 != nil && !errors.Is(err1, http.ErrServerClosed) {
+			app.logger.Error(app.prefixedMsg("admin server failed"), zap.Error(err1))
+		}
+	}()
+	app.logger.Info(app.prefixedMsg("admin server listen on ", l.Addr().String()))
+	return nil
+}
+
+func (app *Application) stopAdmin(ctx context.Context) error {
+	srv := app.admin
+	if srv == nil {
+		return nil
+	}
+	app.admin = nil
+	err := srv.Shutdown(ctx)
+	// the serve goroutine may not track the listener yet
+	if closeErr := app.adminListener.Close(); closeErr != nil && !errors.Is(closeErr, net.ErrClosed) {
+		err = multierr.Append(err, closeErr)
+	}
+	app.adminListener = nil
+	return err
+}
+
+func (app *Application) adminHealth(readiness bool) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		report := app.Health(r.Context())
+		code := http.StatusOK
+		if (readiness && !report.Ready) || (!readiness && !report.Healthy) {
+			code = http.StatusServiceUnavailable
+		}
+		writeJson(w, code, report)
+	}
+}
+
+func (app *Application) adminServers(w http.ResponseWriter, _ *http.Request) {
+	list := make([]ServerReport, 0)
+	for _, typ := range app.ServerTypes() {
+		for _, etServers := range app.GetTypeServers(typ) {
+			for _, s := range etServers {
+				list = append(list, ServerReport{
+					Id:      s.ID(),
+					Name:    s.Name(),
+					Type:    s.Type().String(),
+					EndType: s.EndType().String(),
+				})
+			}
+		}
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].Type+list[i].EndType+list[i].Id < list[j].Type+list[j].EndType+list[j].Id
+	})
+	writeJson(w, http.StatusOK, list)
+}
+
+func (app *Application) adminCluster(w http.ResponseWriter, _ *http.Request) {
+	writeJson(w, http.StatusOK, ClusterReport{
+		Id:   app.cluster.Id(),
+		Name: app.cluster.Name(),
+		App:  app.name,
+	})
+}
+
+// adminRegistry list the register center entries of the cluster, or the entries registered by the application if the register center can not list
+func (app *Application) adminRegistry(w http.ResponseWriter, r *http.Request) {
+	l, ok := app.register.(regCenter.Lister)
+	if !ok {
+		writeJson(w, http.StatusOK, app.RegisteredKvs())
+		return
+	}
+	kvs, err := l.List(r.Context(), app.cluster.Id()+"/")
+	if err != nil {
+		writeJson(w, http.StatusBadGateway, map[string]string{"error": err.Error()})
+		return
+	}
+	writeJson(w, http.StatusOK, kvs)
+}
+
+func (app *Application) adminLogLevel(trace bool) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if trace {
+			app.logCnf.GetTraceLevel().ServeHTTP(w, r)
+		} else {
+			app.logCnf.GetLevel().ServeHTTP(w, r)
+		}
+	}
+}
+
+func (app *Application) adminDebug(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+	case http.MethodPut, http.MethodDelete:
+		o, ok := app.debugger.(debug.Overrider)
+		if !ok {
+			writeJson(w, http.StatusNotImplemented, map[string]string{"error": "debugger not overridable"})
+			return
+		}
+		// a null value or DELETE clears the override, the dynamic source applies again
+		var d struct {
+			Debug *bool `json:"debug"`
+		}
+		if r.Method == http.MethodPut {
+			if err := json.NewDecoder(r.Body).Decode(&d); err != nil {
+				writeJson(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+				return
+			}
+		}
+		o.Override(d.Debug)
+		if d.Debug == nil {
+			app.logger.Info(app.prefixedMsg("debug override cleared by admin"))
+		} else {
+			app.logger.Info(app.prefixedMsg("debug overridden by admin"), zap.Bool("debug", *d.Debug))
+		}
+	default:
+		writeJson(w, http.StatusMethodNotAllowed, map[string]string{"error": "only GET, PUT and DELETE are supported"})
+		return
+	}
+	resp := adminDebug{Debug: app.debugger.Debug()}
+	if o, ok := app.debugger.(debug.Overrider); ok {
+		resp.Overridden = o.Overridden()
+	}
+	writeJson(w, http.StatusOK, resp)
+}
+
+func writeJson(w http.ResponseWriter, code int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	_ = json.NewEncoder(w).Encode(v)
+}
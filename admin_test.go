@@ -0,0 +1,112 @@
+package application_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/obnahsgnaw/application"
+	"github.com/obnahsgnaw/application/service/regCenter"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+func TestAdminRegistry(t *testing.T) {
+	local, _ := regCenter.NewLocalRegister(context.Background())
+	defer local.Release()
+	app := newTestApp(t, application.Register(local, 5))
+	s := newRegServer("auth", &recorder{}, map[string]string{"a": "1"})
+	mustAdd(t, app, s)
+	if err := app.Run(); err != nil {
+		t.Fatal(err)
+	}
+	defer app.Release()
+	// entries registered by the other instances of the cluster
+	if err := local.Register(context.Background(), "dev/other/a", "2", 0); err != nil {
+		t.Fatal(err)
+	}
+	if err := local.Register(context.Background(), "prod/other/a", "3", 0); err != nil {
+		t.Fatal(err)
+	}
+
+	rec := httptest.NewRecorder()
+	app.AdminHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/registry", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status 200 need, but %d", rec.Code)
+	}
+	var kvs map[string]string
+	if err := json.Unmarshal(rec.Body.Bytes(), &kvs); err != nil {
+		t.Fatal(err)
+	}
+	if len(kvs) != 2 || kvs["dev/other/a"] != "2" {
+		t.Errorf("own and other cluster entries need, but %v", kvs)
+	}
+	if _, ok := kvs["prod/other/a"]; ok {
+		t.Error("other cluster entries excluded need, but listed")
+	}
+}
+
+func TestAdminStoppedOnRunFailure(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := l.Addr().String()
+	_ = l.Close()
+
+	app := newTestApp(t, application.Admin(addr))
+	s := newTestServer("api", &recorder{})
+	s.startErr = errors.New("start refused")
+	mustAdd(t, app, s)
+	if err = app.Run(); err == nil {
+		t.Fatal("run error need, but nil")
+	}
+	if l, err = net.Listen("tcp", addr); err != nil {
+		t.Fatalf("admin listener closed need, but %v", err)
+	}
+	_ = l.Close()
+}
+
+func TestAdminDebugOverride(t *testing.T) {
+	var source int32
+	app := newTestApp(t, application.Debug(func() bool { return atomic.LoadInt32(&source) == 1 }))
+	h := app.AdminHandler()
+	call := func(method, body string) (d struct {
+		Debug      bool `json:"debug"`
+		Overridden bool `json:"overridden"`
+	}) {
+		t.Helper()
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(method, "/debug", strings.NewReader(body)))
+		if rec.Code != http.StatusOK {
+			t.Fatalf("status 200 need, but %d %s", rec.Code, rec.Body)
+		}
+		if err := json.Unmarshal(rec.Body.Bytes(), &d); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+
+	if d := call(http.MethodPut, `{"debug":true}`); !d.Debug || !d.Overridden {
+		t.Errorf("overridden debug need, but %+v", d)
+	}
+	atomic.StoreInt32(&source, 0)
+	if d := call(http.MethodGet, ""); !d.Debug {
+		t.Errorf("override kept over the source need, but %+v", d)
+	}
+	if d := call(http.MethodDelete, ""); d.Debug || d.Overridden {
+		t.Errorf("source value after cleared need, but %+v", d)
+	}
+	// the source applies again
+	atomic.StoreInt32(&source, 1)
+	if d := call(http.MethodGet, ""); !d.Debug {
+		t.Errorf("source value need, but %+v", d)
+	}
+	call(http.MethodPut, `{"debug":false}`)
+	if d := call(http.MethodPut, `{"debug":null}`); !d.Debug || d.Overridden {
+		t.Errorf("null clears the override need, but %+v", d)
+	}
+}
@@ -2,49 +2,103 @@ package application
 
 import (
 	"context"
+	"errors"
 	"github.com/obnahsgnaw/application/endtype"
+	"github.com/obnahsgnaw/application/pkg/config"
 	"github.com/obnahsgnaw/application/pkg/debug"
 	"github.com/obnahsgnaw/application/pkg/dynamic"
 	"github.com/obnahsgnaw/application/pkg/logging/logger"
+	"github.com/obnahsgnaw/application/pkg/metrics"
 	"github.com/obnahsgnaw/application/pkg/signals"
+	"github.com/obnahsgnaw/application/pkg/tracing"
 	"github.com/obnahsgnaw/application/pkg/utils"
 	"github.com/obnahsgnaw/application/servertype"
 	"github.com/obnahsgnaw/application/service/event"
 	"github.com/obnahsgnaw/application/service/regCenter"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/multierr"
 	"go.uber.org/zap"
-	"strconv"
+	"net"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
 )
 
-type Server interface {
-	ID() string
-	Name() string
-	Type() servertype.ServerType
-	EndType() endtype.EndType
-	Run(func(error))
-	Release()
-}
-
 // application -->  server -->  end-type --> service
 
 // Application identify a project
 type Application struct {
-	ctx         context.Context
-	cancel      context.CancelFunc
-	name        string
-	cluster     *Cluster
-	logger      *zap.Logger
-	logCnf      *logger.Config
-	logCus      bool
-	debugger    debug.Debugger
-	event       *event.Manger
-	register    regCenter.Register
-	cusRegister bool
-	servers     map[servertype.ServerType]map[endtype.EndType]map[string]Server
-	errs        []error
-	releases    []func()
-	callbacks   []func()
-	children    []*Application
-	regTtl      int64
+	ctx              context.Context
+	cancel           context.CancelFunc
+	name             string
+	cluster          *Cluster
+	logger           *zap.Logger
+	logCnf           *logger.Config
+	logCus           bool
+	debugger         debug.Debugger
+	event            *event.Manger
+	register         regCenter.Register
+	cusRegister      bool
+	servers          map[servertype.ServerType]map[endtype.EndType]map[string]Server
+	levels           [][]Server
+	srvBooting       bool
+	srvRunning       bool
+	srvPending       []Server
+	registered       map[string]*registration
+	srvMu            sync.RWMutex
+	regMu            sync.RWMutex
+	adminAddr        string
+	logLevelWatch    bool
+	logLevelWatchKey string
+	configSources    []config.Source
+	admin            *http.Server
+	adminListener    net.Listener
+	errs             []error
+	errMu            sync.Mutex
+	ran              bool
+	released         int32
+	state            int32
+	releases         []func()
+	callbacks        []func()
+	children         []*Application
+	parent           *Application
+	childMu          sync.Mutex
+	cusDebug         bool
+	eventInitialized bool
+	hooks            map[HookStage][]namedHook
+	hookMu           sync.Mutex
+	defRestartPolicy RestartPolicy
+	restarts         map[string]int
+	restartedAt      map[string]time.Time
+	stopping         map[string]bool
+	restartMu        sync.Mutex
+	reloads          []func() error
+	reloadMu         sync.Mutex
+	signals          *signals.Router
+	signalsMu        sync.Mutex
+	signalEnabled    bool
+	stopCh           chan struct{}
+	fatalCh          chan error
+	election         regCenter.Election
+	electionVal      string
+	singleInstance   bool
+	leadership       regCenter.Leadership
+	leader           bool
+	leaderLevels     [][]Server
+	leaderCallbacks  []func(bool)
+	leaderMu         sync.Mutex
+	campaignCancel   context.CancelFunc
+	metrics          *metrics.Registry
+	cusMetrics       bool
+	builtin          *builtinMetrics
+	tracerProvider   trace.TracerProvider
+	reportFile       string
+	regTtl           int64
+
+	shutdownTimeout      time.Duration
+	healthCheckTimeout   time.Duration
+	serverReleaseTimeout time.Duration
 }
 
 // New return a new application
@@ -54,18 +108,32 @@ func New(name string, options ...Option) *Application {
 		name = "default"
 	}
 	s := &Application{
-		name:     name,
-		ctx:      ctx,
-		cancel:   cancel,
-		cluster:  NewCluster("dev", "Dev"),
-		debugger: debug.New(dynamic.NewBool(func() bool { return true })),
-		event:    event.New(),
-		register: regCenter.NewNone(),
-		servers:  make(map[servertype.ServerType]map[endtype.EndType]map[string]Server),
-		regTtl:   5,
-		logCnf:   &logger.Config{},
+		name:             name,
+		ctx:              ctx,
+		cancel:           cancel,
+		cluster:          NewCluster("dev", "Dev"),
+		debugger:         debug.New(dynamic.NewBool(func() bool { return true })),
+		event:            event.New(),
+		register:         regCenter.NewNone(),
+		servers:          make(map[servertype.ServerType]map[endtype.EndType]map[string]Server),
+		registered:       make(map[string]*registration),
+		restarts:         make(map[string]int),
+		restartedAt:      make(map[string]time.Time),
+		stopping:         make(map[string]bool),
+		defRestartPolicy: RestartPolicy{Mode: RestartNever},
+		stopCh:           make(chan struct{}, 1),
+		fatalCh:          make(chan error, 1),
+		signalEnabled:    true,
+		regTtl:           5,
+		logCnf:           &logger.Config{},
+		metrics:          metrics.NewRegistry(),
+
+		shutdownTimeout:      30 * time.Second,
+		healthCheckTimeout:   5 * time.Second,
+		serverReleaseTimeout: 10 * time.Second,
 	}
 	s.With(options...)
+	s.initMetrics()
 	if s.logger == nil {
 		_ = s.initLogger()
 	}
@@ -125,42 +193,146 @@ func (app *Application) Event() *event.Manger {
 	return app.event
 }
 
-// AddServer add a server
-func (app *Application) AddServer(server Server) {
+// AddServer add a server, the server is started and registered if the application is running,
+// a server implements neither CallbackServer nor LifecycleServer or with an added id is rejected
+func (app *Application) AddServer(server Server) error {
 	if server == nil {
-		return
+		return nil
+	}
+	if err := validServer(server); err != nil {
+		return app.error("add server failed", err)
 	}
+	app.srvMu.Lock()
 	if _, ok := app.servers[server.Type()]; !ok {
 		app.servers[server.Type()] = make(map[endtype.EndType]map[string]Server)
 	}
 	if _, ok := app.servers[server.Type()][server.EndType()]; !ok {
 		app.servers[server.Type()][server.EndType()] = make(map[string]Server)
 	}
+	if _, ok := app.servers[server.Type()][server.EndType()][server.ID()]; ok {
+		app.srvMu.Unlock()
+		return app.error("add server failed", newServerError(server, "add", errors.New("server id already added")))
+	}
 	app.servers[server.Type()][server.EndType()][server.ID()] = server
+	// servers added while booting are started by Run once ready
+	running := app.srvRunning
+	if !running && app.srvBooting {
+		app.srvPending = append(app.srvPending, server)
+	}
+	app.srvMu.Unlock()
+	if !running {
+		return nil
+	}
+
+	return app.startLiveServer(server)
+}
+
+// startLiveServer start a server added while the application is running, leader only servers wait for the leadership
+func (app *Application) startLiveServer(server Server) error {
+	app.fireServerEvent(ServerAddedTopic, server)
+	if isLeaderOnly(server) && !app.addLeaderServer(server) {
+		return nil
+	}
+
+	return app.startAddedServer(server)
+}
+
+// bootServers mark the application booting and return the servers to boot, later added servers are pending until ready
+func (app *Application) bootServers() []Server {
+	app.srvMu.Lock()
+	defer app.srvMu.Unlock()
+	app.srvBooting = true
+	return app.listServers()
 }
 
-// DelServer del added server
-func (app *Application) DelServer(server Server) {
+// startPending mark the application running and start the servers added while booting
+func (app *Application) startPending() {
+	app.srvMu.Lock()
+	app.srvBooting = false
+	app.srvRunning = true
+	pending := app.srvPending
+	app.srvPending = nil
+	app.srvMu.Unlock()
+	for _, s := range pending {
+		if err := app.startLiveServer(s); err != nil {
+			app.logger.Error(app.prefixedMsg(err.Error()), zap.String("server_id", s.ID()))
+		}
+	}
+}
+
+// stopLive stop starting the added servers
+func (app *Application) stopLive() {
+	app.srvMu.Lock()
+	app.srvBooting = false
+	app.srvRunning = false
+	app.srvPending = nil
+	app.srvMu.Unlock()
+}
+
+// DelServer del added server, the server is unregistered and released if it is started
+func (app *Application) DelServer(server Server) error {
 	if server == nil {
-		return
+		return nil
 	}
+	app.srvMu.Lock()
 	if _, ok := app.servers[server.Type()]; ok {
-		if _, ok = app.servers[server.Type()][server.EndType()]; !ok {
+		if _, ok = app.servers[server.Type()][server.EndType()]; ok {
 			delete(app.servers[server.Type()][server.EndType()], server.ID())
 		}
 	}
+	for i, s := range app.srvPending {
+		if serverKey(s) == serverKey(server) {
+			app.srvPending = append(app.srvPending[:i:i], app.srvPending[i+1:]...)
+			break
+		}
+	}
+	started := app.removeStarted(server)
+	app.srvMu.Unlock()
+	if isLeaderOnly(server) {
+		app.delLeaderServer(server)
+	}
+	if !started {
+		return nil
+	}
+	defer app.fireServerEvent(ServerRemovedTopic, server)
+
+	return app.stopRemovedServer(server)
+}
+
+func (app *Application) serverList() []Server {
+	app.srvMu.RLock()
+	defer app.srvMu.RUnlock()
+	return app.listServers()
+}
+
+// listServers return the added servers, need srvMu locked
+func (app *Application) listServers() (list []Server) {
+	for _, typeServers := range app.servers {
+		for _, etServers := range typeServers {
+			for _, s := range etServers {
+				list = append(list, s)
+			}
+		}
+	}
+	return
 }
 
 // GetTypeServers return servers
 func (app *Application) GetTypeServers(typ servertype.ServerType) map[endtype.EndType]map[string]Server {
+	ss := make(map[endtype.EndType]map[string]Server)
 	if typ == "" {
-		return make(map[endtype.EndType]map[string]Server)
-	}
-	if ss, ok := app.servers[typ]; ok {
 		return ss
 	}
+	app.srvMu.RLock()
+	defer app.srvMu.RUnlock()
+	for et, etServers := range app.servers[typ] {
+		ss[et] = make(map[string]Server, len(etServers))
+		for id, s := range etServers {
+			ss[et][id] = s
+		}
+	}
 
-	return make(map[endtype.EndType]map[string]Server)
+	return ss
 }
 
 // GetTypeServer return server
@@ -168,6 +340,8 @@ func (app *Application) GetTypeServer(typ servertype.ServerType, et endtype.EndT
 	if id == "" {
 		return nil, false
 	}
+	app.srvMu.RLock()
+	defer app.srvMu.RUnlock()
 	if ss, ok := app.servers[typ]; ok {
 		if s, ok := ss[et]; ok {
 			if s1, ok := s[id]; ok {
@@ -180,51 +354,96 @@ func (app *Application) GetTypeServer(typ servertype.ServerType, et endtype.EndT
 	return nil, false
 }
 
-// Run application
-func (app *Application) Run(failedCb func(err error)) {
-	defer app.handleCallback()
+// ServerTypes return the types of the added servers
+func (app *Application) ServerTypes() (types []servertype.ServerType) {
+	app.srvMu.RLock()
+	defer app.srvMu.RUnlock()
+	for typ := range app.servers {
+		types = append(types, typ)
+	}
+	return
+}
+
+// Run application, a failed boot rolls back the started servers and sub-applications and returns a *RunError
+// Run replaces the former Run(failedCb func(error)): call it as `if err := app.Run(); err != nil { ... }` and handle the error where failedCb was
+func (app *Application) Run() (err error) {
+	app.ran = true
+	app.setState(Starting)
 	if app.logCus {
-		if err := app.initLogger(); err != nil {
-			failedCb(err)
+		if err = app.initLogger(); err != nil {
+			return app.runError("logger", err, nil)
 		}
 	}
 	app.logger.Info(app.prefixedMsg("init starting..."))
-	app.displayConfig()
-	if !app.initEvent(func(err error) {
-		failedCb(app.error("init event failed", err))
-	}) {
-		return
+	if l, ok := app.register.(regCenter.LoggerSetter); ok && app.cusRegister {
+		l.SetLogger(app.logger)
+	}
+	if err = app.runHooks(app.ctx, &HookEvent{Stage: BeforeRun}); err != nil {
+		return app.runError(BeforeRun.String(), err, nil)
+	}
+	if err = app.displayConfig(); err != nil {
+		return app.runError("startup report", err, nil)
+	}
+	if err = app.startAdmin(); err != nil {
+		return app.runError("admin", err, nil)
+	}
+	if err = app.watchLogLevel(); err != nil {
+		return app.runError("log level watch", err, nil)
+	}
+	if err = app.initEvent(); err != nil {
+		return app.runError("event", err, nil)
 	}
 	app.logger.Info(app.prefixedMsg("event manager initialized"))
+	if err = app.watchConfig(); err != nil {
+		return app.runError("config watch", err, nil)
+	}
+	levels, err := sortServers(app.bootServers())
+	if err != nil {
+		return app.runError("sort", err, nil)
+	}
+	levels, leaderLevels := splitLeaderLevels(levels)
+	if err = app.checkElection(leaderLevels); err != nil {
+		return app.runError("election", err, nil)
+	}
+	var startedLevels [][]Server
 	hadServer := false
-	for _, typeServers := range app.servers {
-		for _, etServers := range typeServers {
-			for _, s := range etServers {
-				app.logger.Debug(app.prefixedMsg(s.EndType().String(), " ", s.Type().String(), " server[", s.Name(), "] init starting..."))
-				s.Run(failedCb)
-				app.logger.Debug(app.prefixedMsg(s.EndType().String(), " ", s.Type().String(), " server[", s.Name(), "] initialized"))
-				hadServer = true
+	for _, level := range levels {
+		var started []Server
+		for _, s := range level {
+			app.logger.Debug(app.prefixedMsg(serverDesc(s), " init starting..."))
+			if err = app.startServer(app.ctx, s); err != nil {
+				app.runFailureHooks(&HookEvent{Stage: OnServerFailed, Server: s, Err: err})
+				return app.runError("start", err, app.rollback(append(startedLevels, started), nil))
 			}
+			started = append(started, s)
+			app.logger.Debug(app.prefixedMsg(serverDesc(s), " initialized"))
+			hadServer = true
 		}
+		startedLevels = append(startedLevels, started)
 	}
+	app.setLevels(startedLevels)
 	if !hadServer {
 		app.logger.Warn(app.prefixedMsg("services initialized, but no services registered"))
 	} else {
 		app.logger.Info(app.prefixedMsg("services initialized"))
 	}
-	if len(app.children) > 0 {
-		for _, sub := range app.children {
-			sub.With(Context(app.ctx))
-			sub.debugger = app.debugger
-			sub.logger = app.logger.Named(sub.name)
-			sub.logCnf = app.logCnf
-			if sub.register == nil {
-				sub.register = app.register
-				sub.regTtl = app.regTtl
+	for _, level := range startedLevels {
+		for _, s := range level {
+			if err = app.registerServer(s); err != nil {
+				return app.runError("register", err, app.rollback(startedLevels, nil))
+			}
+		}
+	}
+	if err = app.runHooks(app.ctx, &HookEvent{Stage: AfterServersStarted}); err != nil {
+		return app.runError(AfterServersStarted.String(), err, app.rollback(startedLevels, nil))
+	}
+	if subs := app.Children(); len(subs) > 0 {
+		var children []*Application
+		for _, sub := range subs {
+			if err = app.runChild(sub); err != nil {
+				return app.runError("sub-application", err, app.rollback(startedLevels, children))
 			}
-			app.logger.Debug(app.prefixedMsg("sub-application[", sub.name, "] init starting..."))
-			sub.Run(failedCb)
-			app.logger.Debug(app.prefixedMsg("sub-application[", sub.name, "] initialized"))
+			children = append(children, sub)
 		}
 		app.logger.Info(app.prefixedMsg("sub-applications initialized"))
 	} else {
@@ -233,30 +452,93 @@ func (app *Application) Run(failedCb func(err error)) {
 	if !app.cusRegister {
 		app.logger.Warn(app.prefixedMsg("register, no server-register registered"))
 	}
+	app.setState(Ready)
+	app.startPending()
+	app.startElection(leaderLevels)
 	app.logger.Info(app.prefixedMsg("initialized"))
+	app.handleCallback()
+	return nil
 }
 
-func (app *Application) Wait() {
-	signals.Listen(nil)
+// Wait until a shutdown signal, the context done, a fatal server failure or Stop called, the reload and dump signals are handled while waiting
+// With the default RestartNever policy any server failure after the boot is fatal and Wait returns StopByServerFailure,
+// set DefaultRestartPolicy or implement Restartable to restart the failed servers instead, a failed sub-application is only stopped
+func (app *Application) Wait() *StopReason {
+	var sigDone <-chan struct{}
+	if app.signalEnabled {
+		r := app.newSignalRouter()
+		r.Start()
+		app.signalsMu.Lock()
+		app.signals = r
+		app.signalsMu.Unlock()
+		sigDone = r.Done()
+	}
 	app.logger.Info(app.prefixedMsg("started and serving..."))
-	signals.Wait()
+	var reason *StopReason
+	select {
+	case <-sigDone:
+		reason = &StopReason{Cause: StopBySignal, Signal: app.signalRouter().Received()}
+	case <-app.ctx.Done():
+		reason = &StopReason{Cause: StopByContext, Err: app.ctx.Err()}
+	case err := <-app.fatalCh:
+		reason = &StopReason{Cause: StopByServerFailure, Err: err}
+	case <-app.stopCh:
+		reason = &StopReason{Cause: StopByCall}
+	}
+	app.setState(Draining)
 	app.cancel()
-	app.logger.Info(app.prefixedMsg("down"))
+	app.logger.Info(app.prefixedMsg("down, ", reason.String()))
+	return reason
 }
 
-// Release stop and release application
-func (app *Application) Release() {
-	for _, typeServers := range app.servers {
-		for _, etServers := range typeServers {
-			for _, s := range etServers {
-				s.Release()
-			}
-		}
+// signalRouter return the signal router started by Wait, nil before
+func (app *Application) signalRouter() *signals.Router {
+	app.signalsMu.Lock()
+	defer app.signalsMu.Unlock()
+	return app.signals
+}
+
+// Release stop and release application within the shutdown timeout, return the aggregated release errors, the later calls do nothing
+func (app *Application) Release() error {
+	if !atomic.CompareAndSwapInt32(&app.released, 0, 1) {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), app.shutdownTimeout)
+	defer cancel()
+	err := app.runHooks(ctx, &HookEvent{Stage: BeforeShutdown})
+	err = multierr.Append(err, app.release(ctx))
+	err = multierr.Append(err, app.runHooks(ctx, &HookEvent{Stage: AfterShutdown}))
+	if r := app.signalRouter(); r != nil {
+		r.Stop()
+	}
+	if app.logger != nil {
+		app.logReleaseErrors(err)
+		app.logger.Info(app.prefixedMsg("released"))
+		_ = app.logger.Sync()
 	}
+	return err
+}
 
-	if len(app.children) > 0 {
-		for _, sub := range app.children {
-			sub.Release()
+func (app *Application) release(ctx context.Context) (err error) {
+	app.setState(Draining)
+	defer app.setState(Stopped)
+	app.stopLive()
+	// only the started servers are released
+	levels := app.takeLevels()
+	err = app.unregisterServers(ctx)
+	for i := len(levels) - 1; i >= 0; i-- {
+		err = multierr.Append(err, app.releaseLevel(ctx, levels[i]))
+	}
+	err = multierr.Append(err, app.resignLeadership(ctx))
+
+	if subs := app.Children(); len(subs) > 0 {
+		for _, sub := range subs {
+			if app.ran && sub.State() == Stopped {
+				continue
+			}
+			if subErr := sub.release(ctx); subErr != nil {
+				err = multierr.Append(err, app.error("sub-application["+sub.name+"] release failed", subErr))
+			}
 		}
 	}
 
@@ -265,11 +547,14 @@ func (app *Application) Release() {
 			r()
 		}
 	}
-
-	if app.logger != nil {
-		app.logger.Info(app.prefixedMsg("released"))
-		_ = app.logger.Sync()
+	// a stopped child frees its admin address for the restart
+	if adminErr := app.stopAdmin(ctx); adminErr != nil {
+		err = multierr.Append(err, app.error("admin server shutdown failed", adminErr))
 	}
+	// stop the watchers and the exit goroutines bound to the application context
+	app.cancel()
+
+	return
 }
 
 func (app *Application) AddRelease(r func()) {
@@ -278,21 +563,16 @@ func (app *Application) AddRelease(r func()) {
 	}
 }
 
-// AddChild add sub application
-func (app *Application) AddChild(apps ...*Application) {
-	if len(apps) > 0 {
-		for _, a := range apps {
-			if a != nil && a.cluster != nil && a.cluster.id == app.cluster.id && a.name != app.name {
-				app.children = append(app.children, a)
-			}
-		}
-	}
-}
-
 // DoRegister register
 func (app *Application) DoRegister(regInfo *regCenter.RegInfo, cb func(string)) error {
-	for k, v := range regInfo.Kvs() {
-		if err := app.register.Register(app.ctx, k, v, regInfo.Ttl); err != nil {
+	kvs, err := regInfo.EncodeKvs()
+	if err != nil {
+		return app.error("register failed", err)
+	}
+	for k, v := range kvs {
+		err := app.register.Register(app.ctx, k, v, regInfo.Ttl)
+		app.observeRegister(metricOpRegister, err)
+		if err != nil {
 			return app.error("register failed", err)
 		}
 		if cb != nil {
@@ -304,8 +584,18 @@ func (app *Application) DoRegister(regInfo *regCenter.RegInfo, cb func(string))
 
 // DoUnregister unregister
 func (app *Application) DoUnregister(regInfo *regCenter.RegInfo, cb func(string)) error {
-	for k := range regInfo.Kvs() {
-		if err := app.register.Unregister(app.ctx, k); err != nil {
+	return app.doUnregister(app.ctx, regInfo, cb)
+}
+
+func (app *Application) doUnregister(ctx context.Context, regInfo *regCenter.RegInfo, cb func(string)) error {
+	kvs, err := regInfo.EncodeKvs()
+	if err != nil {
+		return app.error("unregister failed", err)
+	}
+	for k := range kvs {
+		err := app.register.Unregister(ctx, k)
+		app.observeRegister(metricOpUnregister, err)
+		if err != nil {
 			return app.error("unregister failed", err)
 		}
 		if cb != nil {
@@ -321,40 +611,21 @@ func (app *Application) RegisterCallback(cb func()) {
 	}
 }
 
-func (app *Application) initEvent(failedCb func(err error)) bool {
+func (app *Application) initEvent() error {
+	if app.eventInitialized {
+		return nil
+	}
 	if err := event.Init(app.event); err != nil {
-		failedCb(err)
-		return false
+		return err
 	}
-	return true
+	app.eventInitialized = true
+	return nil
 }
 
 func (app *Application) handleCallback() {
 	for _, cb := range app.callbacks {
 		cb()
 	}
-	if len(app.children) > 0 {
-		for _, sub := range app.children {
-			sub.handleCallback()
-		}
-	}
-}
-
-func (app *Application) displayConfig() {
-	debugDesc := "debug=false"
-	if app.debugger.Debug() {
-		debugDesc = "debug=true"
-	}
-	regDesc := "register-set=false"
-	if app.register != nil {
-		regDesc = "register-set=true"
-	}
-	app.logger.Debug(app.prefixedMsg(utils.ToStr(
-		"config: cluster=", app.cluster.String(),
-		",", debugDesc,
-		",", regDesc,
-		",register-ttl=", strconv.FormatInt(app.regTtl, 10),
-	)))
 }
 
 func (app *Application) prefixedMsg(msg ...string) string {
@@ -369,10 +640,24 @@ func (app *Application) initLogger() (err error) {
 	if err != nil {
 		return
 	}
-	app.logger = app.logger.Named(app.name)
+	app.logger = app.logger.Named(app.name).WithOptions(zap.WrapCore(tracing.WrapCore))
 	return
 }
 
+func (app *Application) runError(stage string, err, rollback error) error {
+	ctx, cancel := context.WithTimeout(context.Background(), app.shutdownTimeout)
+	defer cancel()
+	if adminErr := app.stopAdmin(ctx); adminErr != nil {
+		rollback = multierr.Append(rollback, app.error("admin server shutdown failed", adminErr))
+	}
+	e := &RunError{App: app.name, Stage: stage, Err: err, Rollback: rollback}
+	app.stopLive()
+	app.cancel()
+	app.setState(Stopped)
+	app.logger.Error(app.prefixedMsg(e.Error()))
+	return e
+}
+
 func (app *Application) error(msg string, err error) error {
 	return utils.TitledError("application["+app.name+"] error", msg, err)
 }
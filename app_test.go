@@ -0,0 +1,107 @@
+package application_test
+
+import (
+	"context"
+	"github.com/obnahsgnaw/application"
+	"github.com/obnahsgnaw/application/endtype"
+	"github.com/obnahsgnaw/application/servertype"
+	"sync"
+	"testing"
+	"time"
+)
+
+type recorder struct {
+	mu     sync.Mutex
+	events []string
+}
+
+func (r *recorder) add(ev string) {
+	r.mu.Lock()
+	r.events = append(r.events, ev)
+	r.mu.Unlock()
+}
+
+func (r *recorder) list() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]string(nil), r.events...)
+}
+
+// index return the position of the event, -1 if not recorded
+func (r *recorder) index(ev string) int {
+	for i, e := range r.list() {
+		if e == ev {
+			return i
+		}
+	}
+	return -1
+}
+
+// testServer a lifecycle server records its starts and stops
+type testServer struct {
+	id        string
+	deps      []string
+	priority  int
+	rec       *recorder
+	startErr  error
+	stopDelay time.Duration
+	exited    chan error
+}
+
+func newTestServer(id string, rec *recorder, deps ...string) *testServer {
+	return &testServer{id: id, deps: deps, rec: rec, exited: make(chan error, 1)}
+}
+
+func (s *testServer) ID() string                  { return s.id }
+func (s *testServer) Name() string                { return s.id }
+func (s *testServer) Type() servertype.ServerType { return servertype.Rpc }
+func (s *testServer) EndType() endtype.EndType    { return endtype.Backend }
+func (s *testServer) DependsOn() []string         { return s.deps }
+func (s *testServer) Priority() int               { return s.priority }
+func (s *testServer) Exited() <-chan error        { return s.exited }
+
+func (s *testServer) Start(context.Context) error {
+	if s.startErr != nil {
+		return s.startErr
+	}
+	s.rec.add("start:" + s.id)
+	return nil
+}
+
+func (s *testServer) Stop(ctx context.Context) error {
+	if s.stopDelay > 0 {
+		select {
+		case <-time.After(s.stopDelay):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	s.rec.add("stop:" + s.id)
+	return nil
+}
+
+func newTestApp(t *testing.T, options ...application.Option) *application.Application {
+	t.Helper()
+	return application.New("test", append([]application.Option{application.DisableSignals()}, options...)...)
+}
+
+func mustAdd(t *testing.T, app *application.Application, servers ...application.Server) {
+	t.Helper()
+	for _, s := range servers {
+		if err := app.AddServer(s); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// waitFor poll the condition until the deadline
+func waitFor(t *testing.T, desc string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("%s need, but timeout", desc)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
@@ -0,0 +1,157 @@
+package application
+
+import (
+	"context"
+	"github.com/obnahsgnaw/application/pkg/utils"
+	"go.uber.org/zap"
+)
+
+// ChildFailedTopic the event topic fired with the *ChildError when a sub-application is stopped by a fatal failure
+const ChildFailedTopic = "child.failed"
+
+// ChildError an error of a sub-application
+type ChildError struct {
+	Name string
+	Err  error
+}
+
+func (e *ChildError) Error() string {
+	return utils.ToStr("sub-application[", e.Name, "] failed: ", e.Err.Error())
+}
+
+func (e *ChildError) Unwrap() error {
+	return e.Err
+}
+
+// AddChild add sub applications, a child should be in the same cluster with a unique name
+func (app *Application) AddChild(apps ...*Application) error {
+	app.childMu.Lock()
+	defer app.childMu.Unlock()
+	for _, a := range apps {
+		if a == nil {
+			continue
+		}
+		if a.cluster == nil || a.cluster.id != app.cluster.id {
+			return app.error("add sub-application["+a.name+"] failed", utils.TitledError("cluster mismatch", "the parent cluster is "+app.cluster.String(), nil))
+		}
+		if a.name == app.name {
+			return app.error("add sub-application["+a.name+"] failed", utils.TitledError("name conflict", "the same name with the parent", nil))
+		}
+		for _, c := range app.children {
+			if c.name == a.name {
+				return app.error("add sub-application["+a.name+"] failed", utils.TitledError("name conflict", "the name is added", nil))
+			}
+		}
+		a.parent = app
+		app.children = append(app.children, a)
+	}
+	return nil
+}
+
+// Child return the sub-application by name
+func (app *Application) Child(name string) (*Application, bool) {
+	app.childMu.Lock()
+	defer app.childMu.Unlock()
+	for _, c := range app.children {
+		if c.name == name {
+			return c, true
+		}
+	}
+	return nil, false
+}
+
+// Children return the sub-applications
+func (app *Application) Children() []*Application {
+	app.childMu.Lock()
+	defer app.childMu.Unlock()
+	return append([]*Application(nil), app.children...)
+}
+
+// StartChild start a stopped sub-application
+func (app *Application) StartChild(name string) error {
+	sub, ok := app.Child(name)
+	if !ok {
+		return app.error("sub-application["+name+"] not found", nil)
+	}
+	if sub.State() != Stopped {
+		return app.error("sub-application["+name+"] is "+sub.State().String(), nil)
+	}
+	return app.runChild(sub)
+}
+
+// StopChild release a started sub-application and cancel its context
+func (app *Application) StopChild(name string) error {
+	sub, ok := app.Child(name)
+	if !ok {
+		return app.error("sub-application["+name+"] not found", nil)
+	}
+	if sub.State() == Stopped {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), app.shutdownTimeout)
+	defer cancel()
+	err := sub.release(ctx)
+	sub.cancel()
+	if err != nil {
+		sub.logReleaseErrors(err)
+		return &ChildError{Name: name, Err: err}
+	}
+	app.logger.Info(app.prefixedMsg("sub-application[", name, "] stopped"))
+	return nil
+}
+
+// runChild inherit the unset logger, debugger, register and metrics from the parent, then run the child
+func (app *Application) runChild(sub *Application) error {
+	sub.With(Context(app.ctx))
+	if !sub.cusDebug {
+		sub.debugger = app.debugger
+	}
+	if !sub.logCus {
+		sub.logger = app.logger.Named(sub.name)
+		sub.logCnf = app.logCnf
+	}
+	if sub.tracerProvider == nil {
+		sub.tracerProvider = app.tracerProvider
+	}
+	if !sub.cusMetrics && sub.metrics != app.metrics {
+		sub.metrics = app.metrics
+		sub.initMetrics()
+	}
+	if !sub.cusRegister {
+		sub.register = app.register
+		sub.regTtl = app.regTtl
+	}
+	app.logger.Debug(app.prefixedMsg("sub-application[", sub.name, "] init starting..."))
+	if err := sub.Run(); err != nil {
+		return &ChildError{Name: sub.name, Err: err}
+	}
+	app.logger.Debug(app.prefixedMsg("sub-application[", sub.name, "] initialized"))
+	return nil
+}
+
+// childFailed collect the server failure of a sub-application
+func (app *Application) childFailed(name string, err error) {
+	err = &ChildError{Name: name, Err: err}
+	app.errMu.Lock()
+	app.errs = append(app.errs, err)
+	app.errMu.Unlock()
+	app.logger.Error(app.prefixedMsg(err.Error()), zap.String("sub_application", name))
+	if app.parent != nil {
+		app.parent.childFailed(app.name, err)
+	}
+}
+
+// childFatal stop the sub-application after its fatal failure, the parent keeps running
+func (app *Application) childFatal(name string, err error) {
+	sub, ok := app.Child(name)
+	if !ok || sub.State() != Ready {
+		return
+	}
+	childErr := &ChildError{Name: name, Err: err}
+	app.logger.Error(app.prefixedMsg(childErr.Error(), ", stopping it"), zap.String("sub_application", name))
+	app.event.NewEvent(ChildFailedTopic, []interface{}{childErr}).Fire()
+	app.runFailureHooks(&HookEvent{Stage: OnChildFailed, Child: sub, Err: childErr})
+	if stopErr := app.StopChild(name); stopErr != nil {
+		app.logger.Error(app.prefixedMsg("sub-application[", name, "] stop failed"), zap.Error(stopErr))
+	}
+}
@@ -0,0 +1,88 @@
+package application_test
+
+import (
+	"context"
+	"errors"
+	"github.com/obnahsgnaw/application"
+	"net"
+	"testing"
+	"time"
+)
+
+func TestChildFatal(t *testing.T) {
+	rec := &recorder{}
+	parent := newTestApp(t)
+	defer parent.Release()
+	child := application.New("child", application.DisableSignals())
+	failing := newTestServer("failing", rec)
+	mustAdd(t, child, failing)
+	mustAdd(t, parent, newTestServer("main", rec))
+	if err := parent.AddChild(child); err != nil {
+		t.Fatal(err)
+	}
+	failed := make(chan *application.HookEvent, 1)
+	parent.AddHook(application.OnChildFailed, "record", func(_ context.Context, e *application.HookEvent) error {
+		failed <- e
+		return nil
+	})
+	if err := parent.Run(); err != nil {
+		t.Fatal(err)
+	}
+
+	failing.exited <- errors.New("crashed")
+	var e *application.HookEvent
+	select {
+	case e = <-failed:
+	case <-time.After(2 * time.Second):
+		t.Fatal("child failed hook need, but timeout")
+	}
+	if e.Child != child {
+		t.Errorf("failed child need, but %v", e.Child)
+	}
+	var childErr *application.ChildError
+	if !errors.As(e.Err, &childErr) || childErr.Name != "child" {
+		t.Errorf("child error need, but %v", e.Err)
+	}
+	waitFor(t, "child stopped", func() bool { return child.State() == application.Stopped })
+	if parent.State() != application.Ready {
+		t.Errorf("parent ready need, but %s", parent.State())
+	}
+	if report := parent.Health(context.Background()); !report.Ready || !report.Healthy {
+		t.Errorf("parent ready with the stopped child need, but %+v", report)
+	}
+	if rec.index("stop:main") != -1 {
+		t.Errorf("parent servers running need, but %v", rec.list())
+	}
+}
+
+func TestChildAdminRestart(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := l.Addr().String()
+	_ = l.Close()
+
+	parent := newTestApp(t)
+	defer parent.Release()
+	child := application.New("child", application.DisableSignals(), application.Admin(addr))
+	if err = parent.AddChild(child); err != nil {
+		t.Fatal(err)
+	}
+	if err = parent.Run(); err != nil {
+		t.Fatal(err)
+	}
+	if err = parent.StopChild("child"); err != nil {
+		t.Fatal(err)
+	}
+	if report := parent.Health(context.Background()); !report.Ready || len(report.Children) != 1 {
+		t.Errorf("parent ready with the stopped child reported need, but %+v", report)
+	}
+	if l, err = net.Listen("tcp", addr); err != nil {
+		t.Fatalf("child admin listener closed need, but %v", err)
+	}
+	_ = l.Close()
+	if err = parent.StartChild("child"); err != nil {
+		t.Fatalf("child restarted need, but %v", err)
+	}
+}
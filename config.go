@@ -0,0 +1,71 @@
+package application
+
+import (
+	"context"
+	"github.com/obnahsgnaw/application/pkg/config"
+	"github.com/obnahsgnaw/application/pkg/logging/logger"
+	"go.uber.org/multierr"
+	"go.uber.org/zap"
+	"time"
+)
+
+// AppConfig the application config, load it by the pkg/config package
+type AppConfig struct {
+	Cluster  ClusterConfig  `json:"cluster" yaml:"cluster"`
+	Debug    bool           `json:"debug" yaml:"debug" long:"debug" description:"Debug mode." required:"false" default:"false"`
+	Log      *logger.Config `json:"log" yaml:"log"`
+	Register RegisterConfig `json:"register" yaml:"register"`
+}
+
+// ClusterConfig the cluster config
+type ClusterConfig struct {
+	Id   string `json:"id" yaml:"id" long:"cluster-id" description:"Cluster id." required:"true" default:"dev"`
+	Name string `json:"name" yaml:"name" long:"cluster-name" description:"Cluster name." required:"false" default:"Dev"`
+}
+
+// RegisterConfig the register center config
+type RegisterConfig struct {
+	Type      string        `json:"type" yaml:"type" long:"register-type" description:"Register center type: none, local, etcd, file." required:"false" default:"none"`
+	Endpoints []string      `json:"endpoints" yaml:"endpoints" long:"register-endpoints" description:"Register center endpoints, comma separated, the file path for the file type." required:"false" default:""`
+	Ttl       int64         `json:"ttl" yaml:"ttl" long:"register-ttl" description:"Register ttl (second)." required:"false" default:"5"`
+	Timeout   time.Duration `json:"timeout" yaml:"timeout" long:"register-timeout" description:"Register operate timeout." required:"false" default:"5s"`
+}
+
+// ConfigChangedTopic the event topic fired with a *config.Change when a watched config source changed
+const ConfigChangedTopic = "config.changed"
+
+func (app *Application) watchConfig() error {
+	for _, src := range app.configSources {
+		if err := src.Watch(app.ctx, app.configChanged); err != nil {
+			return app.error("config source["+src.Name()+"] watch failed", err)
+		}
+	}
+	return nil
+}
+
+// reloadConfig read the config sources again, the contents are fired as changes
+func (app *Application) reloadConfig() (err error) {
+	ctx, cancel := context.WithTimeout(app.ctx, app.shutdownTimeout)
+	defer cancel()
+	for _, src := range app.configSources {
+		r, ok := src.(config.Reader)
+		if !ok {
+			continue
+		}
+		changes, readErr := r.Read(ctx)
+		if readErr != nil {
+			err = multierr.Append(err, app.error("config source["+src.Name()+"] read failed", readErr))
+			continue
+		}
+		for _, c := range changes {
+			app.configChanged(c)
+		}
+	}
+	return
+}
+
+func (app *Application) configChanged(c *config.Change) {
+	app.logger.Info(app.prefixedMsg("config changed"), zap.String("source", c.Source), zap.String("key", c.Key), zap.Bool("deleted", c.Deleted))
+	app.event.NewEvent(ConfigChangedTopic, []interface{}{c}).Fire()
+	app.event.NewEvent(ConfigChangedTopic+"."+c.Source, []interface{}{c}).Fire()
+}
@@ -0,0 +1,128 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"go.uber.org/multierr"
+)
+
+// server lifecycle event topics, the event data is the server
+const (
+	ServerAddedTopic   = "server.added"
+	ServerStartedTopic = "server.started"
+	ServerStoppedTopic = "server.stopped"
+	ServerRemovedTopic = "server.removed"
+)
+
+func (app *Application) fireServerEvent(topic string, s Server) {
+	app.event.NewEvent(topic, []interface{}{s}).Fire()
+}
+
+func (app *Application) setLevels(levels [][]Server) {
+	app.srvMu.Lock()
+	app.levels = levels
+	app.srvMu.Unlock()
+}
+
+// startedLevels return a copy of the started server levels, nil if not started
+func (app *Application) startedLevels() [][]Server {
+	app.srvMu.RLock()
+	defer app.srvMu.RUnlock()
+	if app.levels == nil {
+		return nil
+	}
+	levels := make([][]Server, len(app.levels))
+	for i, level := range app.levels {
+		levels[i] = append([]Server(nil), level...)
+	}
+	return levels
+}
+
+// takeLevels return the started server levels and clear them, the servers are released once
+func (app *Application) takeLevels() [][]Server {
+	app.srvMu.Lock()
+	defer app.srvMu.Unlock()
+	levels := app.levels
+	app.levels = nil
+	return levels
+}
+
+// isLive check the application is running and the server is started
+func (app *Application) isLive(s Server) bool {
+	app.srvMu.RLock()
+	defer app.srvMu.RUnlock()
+	if !app.srvRunning {
+		return false
+	}
+	key := serverKey(s)
+	for _, level := range app.levels {
+		for _, s1 := range level {
+			if serverKey(s1) == key {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// removeStarted remove the server from the started levels, need srvMu locked
+func (app *Application) removeStarted(s Server) bool {
+	key := serverKey(s)
+	for i, level := range app.levels {
+		for j, s1 := range level {
+			if serverKey(s1) == key {
+				app.levels[i] = append(level[:j:j], level[j+1:]...)
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// startAddedServer start and register a server added after the application is running, it is started in a new last level
+func (app *Application) startAddedServer(s Server) error {
+	app.logger.Debug(app.prefixedMsg(serverDesc(s), " init starting..."))
+	if err := app.startServer(app.ctx, s); err != nil {
+		app.runFailureHooks(&HookEvent{Stage: OnServerFailed, Server: s, Err: err})
+		app.srvMu.Lock()
+		delete(app.servers[s.Type()][s.EndType()], s.ID())
+		app.srvMu.Unlock()
+		return app.error("add server failed", err)
+	}
+	app.srvMu.Lock()
+	if !app.srvRunning {
+		// released while starting
+		delete(app.servers[s.Type()][s.EndType()], s.ID())
+		app.srvMu.Unlock()
+		ctx, cancel := context.WithTimeout(context.Background(), app.shutdownTimeout)
+		defer cancel()
+		return app.error("add server failed", multierr.Append(errors.New("application stopped"), app.releaseServer(ctx, s)))
+	}
+	app.levels = append(app.levels, []Server{s})
+	app.srvMu.Unlock()
+	if err := app.registerServer(s); err != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), app.shutdownTimeout)
+		defer cancel()
+		app.srvMu.Lock()
+		delete(app.servers[s.Type()][s.EndType()], s.ID())
+		app.removeStarted(s)
+		app.srvMu.Unlock()
+		return app.error("add server failed", multierr.Append(err, app.releaseServer(ctx, s)))
+	}
+	app.logger.Debug(app.prefixedMsg(serverDesc(s), " initialized"))
+	app.fireServerEvent(ServerStartedTopic, s)
+	return nil
+}
+
+// stopRemovedServer unregister and release a started server removed from the application
+func (app *Application) stopRemovedServer(s Server) error {
+	ctx, cancel := context.WithTimeout(context.Background(), app.shutdownTimeout)
+	defer cancel()
+	err := multierr.Append(app.unregisterServer(ctx, s), app.releaseServer(ctx, s))
+	app.fireServerEvent(ServerStoppedTopic, s)
+	if err != nil {
+		return app.error("delete server failed", err)
+	}
+	app.logger.Debug(app.prefixedMsg(serverDesc(s), " released"))
+	return nil
+}
@@ -0,0 +1,391 @@
+package application
+
+import (
+	"context"
+	"github.com/obnahsgnaw/application/pkg/utils"
+	"github.com/obnahsgnaw/application/service/regCenter"
+	"go.uber.org/multierr"
+	"go.uber.org/zap"
+	"os"
+	"strconv"
+	"time"
+)
+
+// leader election event topics, the event data is the application
+const (
+	LeaderElectedTopic = "leader.elected"
+	LeaderLostTopic    = "leader.lost"
+)
+
+// LeaderOnly a server only started on the elected leader, stopped when the leadership is lost
+type LeaderOnly interface {
+	LeaderOnly() bool
+}
+
+func isLeaderOnly(s Server) bool {
+	l, ok := s.(LeaderOnly)
+	return ok && l.LeaderOnly()
+}
+
+// splitLeaderLevels split the leader-only servers out of the start levels, the level order is kept
+func splitLeaderLevels(levels [][]Server) (normal, leader [][]Server) {
+	for _, level := range levels {
+		var n, l []Server
+		for _, s := range level {
+			if isLeaderOnly(s) {
+				l = append(l, s)
+			} else {
+				n = append(n, s)
+			}
+		}
+		if len(n) > 0 {
+			normal = append(normal, n)
+		}
+		if len(l) > 0 {
+			leader = append(leader, l)
+		}
+	}
+	return
+}
+
+// addLeaderServer add a leader-only server added after the application is running, return it need to start now
+func (app *Application) addLeaderServer(s Server) bool {
+	app.leaderMu.Lock()
+	defer app.leaderMu.Unlock()
+	app.leaderLevels = append(app.leaderLevels, []Server{s})
+	return app.leader
+}
+
+func (app *Application) delLeaderServer(s Server) {
+	key := serverKey(s)
+	app.leaderMu.Lock()
+	defer app.leaderMu.Unlock()
+	for i, level := range app.leaderLevels {
+		for j, s1 := range level {
+			if serverKey(s1) == key {
+				app.leaderLevels[i] = append(level[:j:j], level[j+1:]...)
+				return
+			}
+		}
+	}
+}
+
+// ElectionName return the election name of the application
+func (app *Application) ElectionName() string {
+	return utils.ToStr(app.cluster.Id(), "/election/", app.name)
+}
+
+// ElectionValue return the candidate value of the application
+func (app *Application) ElectionValue() string {
+	if app.electionVal == "" {
+		host, _ := os.Hostname()
+		app.electionVal = utils.ToStr(host, "-", strconv.Itoa(os.Getpid()), "-", app.name)
+	}
+	return app.electionVal
+}
+
+// Election return the leader election, the configured one or provided by the register center, nil if none
+func (app *Application) Election() regCenter.Election {
+	if app.election != nil {
+		return app.election
+	}
+	if e, ok := app.register.(regCenter.Elector); ok {
+		return e.Election()
+	}
+	return nil
+}
+
+// IsLeader return the application is the elected leader
+func (app *Application) IsLeader() bool {
+	app.leaderMu.Lock()
+	defer app.leaderMu.Unlock()
+	return app.leader
+}
+
+// Leader return the candidate value of the current leader
+func (app *Application) Leader(ctx context.Context) (string, error) {
+	e := app.Election()
+	if e == nil {
+		return "", app.error("leader election not supported", nil)
+	}
+	return e.Leader(ctx, app.ElectionName())
+}
+
+// OnLeaderChange add a callback called when the leadership is elected or lost
+func (app *Application) OnLeaderChange(cb func(leader bool)) {
+	if cb != nil {
+		app.leaderMu.Lock()
+		app.leaderCallbacks = append(app.leaderCallbacks, cb)
+		app.leaderMu.Unlock()
+	}
+}
+
+// needElection return there are leader-only servers or leader change callbacks, need leaderMu locked
+func (app *Application) needElection(leaderLevels [][]Server) bool {
+	return len(leaderLevels) > 0 || len(app.leaderLevels) > 0 || len(app.leaderCallbacks) > 0 || app.election != nil
+}
+
+// checkElection fail without a leader election unless running as a single instance, the leader-only servers would start on every instance
+func (app *Application) checkElection(leaderLevels [][]Server) error {
+	app.leaderMu.Lock()
+	need := app.needElection(leaderLevels)
+	app.leaderMu.Unlock()
+	if need && app.Election() == nil && !app.singleInstance {
+		return app.error("leader election not supported by the register, set SingleInstance to run as the only leader", nil)
+	}
+	return nil
+}
+
+// startElection campaign for the leadership if there are leader-only servers or leader change callbacks
+func (app *Application) startElection(leaderLevels [][]Server) {
+	app.leaderMu.Lock()
+	need := app.needElection(leaderLevels)
+	// the leader-only servers added while booting are kept
+	app.leaderLevels = append(leaderLevels, app.leaderLevels...)
+	app.leaderMu.Unlock()
+	if !need {
+		return
+	}
+	e := app.Election()
+	if e == nil {
+		if !app.singleInstance {
+			app.logger.Error(app.prefixedMsg("leader election not supported by the register, the leader-only servers are not started"))
+			return
+		}
+		app.logger.Info(app.prefixedMsg("single instance, run as the leader"))
+		if err := app.becomeLeader(app.ctx, nil); err != nil {
+			app.logger.Error(app.prefixedMsg("leader servers start failed"), zap.Error(err))
+			app.fatal(err)
+		}
+		return
+	}
+	ctx, cancel := context.WithCancel(app.ctx)
+	app.leaderMu.Lock()
+	app.campaignCancel = cancel
+	app.leaderMu.Unlock()
+	go app.campaign(ctx, e)
+}
+
+// campaign for the leadership until ctx done, the campaign is canceled on release
+func (app *Application) campaign(ctx context.Context, e regCenter.Election) {
+	name := app.ElectionName()
+	for {
+		l, err := e.Campaign(ctx, name, app.ElectionValue())
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			app.logger.Error(app.prefixedMsg("leader campaign failed"), zap.Error(err))
+			if !electionBackoff(ctx) {
+				return
+			}
+			continue
+		}
+		if err = app.becomeLeader(ctx, l); err != nil {
+			app.logger.Error(app.prefixedMsg("leader servers start failed, resign"), zap.Error(err))
+			_ = l.Resign(context.Background())
+			if !electionBackoff(ctx) {
+				return
+			}
+			continue
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-l.Lost():
+			app.loseLeader(l)
+		}
+	}
+}
+
+func electionBackoff(ctx context.Context) bool {
+	t := time.NewTimer(time.Second)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-t.C:
+		return true
+	}
+}
+
+// becomeLeader start and register the leader-only servers outside the leader lock, they are appended to the started levels,
+// the leadership is resigned if the application is not ready
+func (app *Application) becomeLeader(ctx context.Context, l regCenter.Leadership) (err error) {
+	app.leaderMu.Lock()
+	if ctx.Err() != nil || app.State() != Ready {
+		app.leaderMu.Unlock()
+		if l != nil {
+			_ = l.Resign(context.Background())
+		}
+		return nil
+	}
+	app.leadership = l
+	levels := copyLevels(app.leaderLevels)
+	app.leaderMu.Unlock()
+
+	started, err := app.startLeaderServers(ctx, levels)
+	app.leaderMu.Lock()
+	if err == nil && (app.leadership != l || ctx.Err() != nil) {
+		// resigned or released while starting
+		app.leaderMu.Unlock()
+		stopCtx, cancel := context.WithTimeout(context.Background(), app.shutdownTimeout)
+		defer cancel()
+		if stopErr := app.stopLeaderServers(stopCtx, started); stopErr != nil {
+			app.logger.Error(app.prefixedMsg("leader servers release failed"), zap.Error(stopErr))
+		}
+		return nil
+	}
+	if err != nil {
+		app.leadership = nil
+		app.leaderMu.Unlock()
+		stopCtx, cancel := context.WithTimeout(context.Background(), app.shutdownTimeout)
+		defer cancel()
+		return multierr.Append(err, app.stopLeaderServers(stopCtx, started))
+	}
+	app.leader = true
+	missed := missedServers(app.leaderLevels, levels)
+	callbacks := append(([]func(bool))(nil), app.leaderCallbacks...)
+	app.leaderMu.Unlock()
+	// leader-only servers added while starting
+	for _, s := range missed {
+		if err = app.startAddedServer(s); err != nil {
+			app.logger.Error(app.prefixedMsg(err.Error()), zap.String("server_id", s.ID()))
+		}
+	}
+	app.logger.Info(app.prefixedMsg("elected as the leader"))
+	app.event.NewEvent(LeaderElectedTopic, []interface{}{app}).Fire()
+	for _, cb := range callbacks {
+		cb(true)
+	}
+	return nil
+}
+
+// startLeaderServers start and register the leader-only server levels, the started levels are returned on failure too
+func (app *Application) startLeaderServers(ctx context.Context, levels [][]Server) (started [][]Server, err error) {
+	for _, level := range levels {
+		var s1 []Server
+		for _, s := range level {
+			if err = app.startServer(ctx, s); err != nil {
+				break
+			}
+			s1 = append(s1, s)
+		}
+		if len(s1) > 0 {
+			started = append(started, s1)
+		}
+		if err != nil {
+			return
+		}
+	}
+	app.srvMu.Lock()
+	if !app.srvRunning {
+		app.srvMu.Unlock()
+		return started, app.error("application stopped", nil)
+	}
+	app.levels = append(app.levels, started...)
+	app.srvMu.Unlock()
+	for _, level := range started {
+		for _, s := range level {
+			if err = app.registerServer(s); err != nil {
+				return
+			}
+		}
+	}
+	return
+}
+
+func copyLevels(levels [][]Server) [][]Server {
+	copied := make([][]Server, len(levels))
+	for i, level := range levels {
+		copied[i] = append([]Server(nil), level...)
+	}
+	return copied
+}
+
+// missedServers return the servers of the levels not in the started levels
+func missedServers(levels, started [][]Server) (missed []Server) {
+	keys := make(map[string]bool)
+	for _, level := range started {
+		for _, s := range level {
+			keys[serverKey(s)] = true
+		}
+	}
+	for _, level := range levels {
+		for _, s := range level {
+			if !keys[serverKey(s)] {
+				missed = append(missed, s)
+			}
+		}
+	}
+	return
+}
+
+// loseLeader unregister and release the leader-only servers
+func (app *Application) loseLeader(l regCenter.Leadership) {
+	app.leaderMu.Lock()
+	if app.leadership != l || !app.leader {
+		app.leaderMu.Unlock()
+		return
+	}
+	app.leader = false
+	app.leadership = nil
+	levels := app.leaderLevels
+	callbacks := append(([]func(bool))(nil), app.leaderCallbacks...)
+	app.leaderMu.Unlock()
+	if app.State() == Draining || app.State() == Stopped {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), app.shutdownTimeout)
+	defer cancel()
+	if err := app.stopLeaderServers(ctx, levels); err != nil {
+		app.logger.Error(app.prefixedMsg("leader servers release failed"), zap.Error(err))
+	}
+	app.logger.Warn(app.prefixedMsg("leadership lost"))
+	app.event.NewEvent(LeaderLostTopic, []interface{}{app}).Fire()
+	for _, cb := range callbacks {
+		cb(false)
+	}
+}
+
+func (app *Application) stopLeaderServers(ctx context.Context, levels [][]Server) (err error) {
+	app.srvMu.Lock()
+	var started [][]Server
+	for _, level := range levels {
+		var s1 []Server
+		for _, s := range level {
+			if app.removeStarted(s) {
+				s1 = append(s1, s)
+			}
+		}
+		started = append(started, s1)
+	}
+	app.srvMu.Unlock()
+	for i := len(started) - 1; i >= 0; i-- {
+		for _, s := range started[i] {
+			err = multierr.Append(err, app.unregisterServer(ctx, s))
+		}
+		err = multierr.Append(err, app.releaseLevel(ctx, started[i]))
+	}
+	return
+}
+
+// resignLeadership stop the campaign and resign the held leadership
+func (app *Application) resignLeadership(ctx context.Context) error {
+	app.leaderMu.Lock()
+	if app.campaignCancel != nil {
+		app.campaignCancel()
+		app.campaignCancel = nil
+	}
+	l := app.leadership
+	app.leadership = nil
+	app.leader = false
+	app.leaderMu.Unlock()
+	if l == nil {
+		return nil
+	}
+	if err := l.Resign(ctx); err != nil {
+		return app.error("leadership resign failed", err)
+	}
+	return nil
+}
@@ -0,0 +1,142 @@
+package application_test
+
+import (
+	"context"
+	"errors"
+	"github.com/obnahsgnaw/application"
+	"github.com/obnahsgnaw/application/service/regCenter"
+	"testing"
+	"time"
+)
+
+// blockingServer a leader-only server blocks its start until released
+type blockingServer struct {
+	*leaderServer
+	release chan struct{}
+}
+
+func (s *blockingServer) Start(ctx context.Context) error {
+	<-s.release
+	return s.leaderServer.Start(ctx)
+}
+
+func TestElectionFailover(t *testing.T) {
+	e := regCenter.NewLocalElection()
+	rec1, rec2 := &recorder{}, &recorder{}
+	app1 := newTestApp(t, application.LeaderElection(e))
+	mustAdd(t, app1, &leaderServer{newTestServer("job", rec1)})
+	app2 := newTestApp(t, application.LeaderElection(e))
+	defer app2.Release()
+	mustAdd(t, app2, &leaderServer{newTestServer("job", rec2)})
+	if err := app1.Run(); err != nil {
+		t.Fatal(err)
+	}
+	waitFor(t, "first leader elected", app1.IsLeader)
+	if err := app2.Run(); err != nil {
+		t.Fatal(err)
+	}
+	if app2.IsLeader() || rec2.index("start:job") != -1 {
+		t.Fatalf("follower need, but leader %v %v", app2.IsLeader(), rec2.list())
+	}
+	_ = app1.Release()
+	if rec1.index("stop:job") == -1 {
+		t.Errorf("released leader job stopped need, but %v", rec1.list())
+	}
+	waitFor(t, "failover", app2.IsLeader)
+	if rec2.index("start:job") == -1 {
+		t.Errorf("new leader job started need, but %v", rec2.list())
+	}
+}
+
+func TestReleaseStopsCampaign(t *testing.T) {
+	e := regCenter.NewLocalElection()
+	leader := newTestApp(t, application.LeaderElection(e))
+	follower := newTestApp(t, application.LeaderElection(e))
+	if err := leader.Run(); err != nil {
+		t.Fatal(err)
+	}
+	waitFor(t, "leader elected", leader.IsLeader)
+	if err := follower.Run(); err != nil {
+		t.Fatal(err)
+	}
+	_ = follower.Release()
+	_ = leader.Release()
+	time.Sleep(20 * time.Millisecond)
+	if val, _ := e.Leader(context.Background(), leader.ElectionName()); val != "" {
+		t.Errorf("no leader after both released need, but %s", val)
+	}
+	if follower.IsLeader() {
+		t.Error("released follower not elected need, but leader")
+	}
+}
+
+func TestLeaderStartOutsideLock(t *testing.T) {
+	rec := &recorder{}
+	app := newTestApp(t, application.LeaderElection(regCenter.NewLocalElection()))
+	defer app.Release()
+	s := &blockingServer{leaderServer: &leaderServer{newTestServer("job", rec)}, release: make(chan struct{})}
+	mustAdd(t, app, s)
+	changes := make(chan bool, 1)
+	if err := app.Run(); err != nil {
+		t.Fatal(err)
+	}
+	done := make(chan struct{})
+	go func() {
+		app.OnLeaderChange(func(leader bool) { changes <- leader })
+		_ = app.IsLeader()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("leader queries not blocked by the starting servers need, but blocked")
+	}
+	close(s.release)
+	select {
+	case leader := <-changes:
+		if !leader {
+			t.Error("elected change need, but lost")
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("elected change need, but timeout")
+	}
+	if rec.index("start:job") == -1 {
+		t.Errorf("job started need, but %v", rec.list())
+	}
+}
+
+func TestLeaderWithoutElection(t *testing.T) {
+	tests := []struct {
+		name    string
+		options []application.Option
+		wantErr bool
+	}{
+		{name: "no election", wantErr: true},
+		{name: "single instance", options: []application.Option{application.SingleInstance()}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := &recorder{}
+			app := newTestApp(t, tt.options...)
+			defer app.Release()
+			mustAdd(t, app, &leaderServer{newTestServer("job", rec)}, newTestServer("api", rec))
+			err := app.Run()
+			if tt.wantErr {
+				var runErr *application.RunError
+				if !errors.As(err, &runErr) || runErr.Stage != "election" {
+					t.Errorf("election run error need, but %v", err)
+				}
+				if len(rec.list()) != 0 {
+					t.Errorf("no server started need, but %v", rec.list())
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !app.IsLeader() || rec.index("start:job") == -1 {
+				t.Errorf("leader with the job started need, but %v %v", app.IsLeader(), rec.list())
+			}
+		})
+	}
+}
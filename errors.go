@@ -0,0 +1,76 @@
+package application
+
+import (
+	"errors"
+	"github.com/obnahsgnaw/application/endtype"
+	"github.com/obnahsgnaw/application/pkg/utils"
+	"github.com/obnahsgnaw/application/servertype"
+	"go.uber.org/multierr"
+)
+
+// ServerError a server lifecycle operation error
+type ServerError struct {
+	ID      string
+	Name    string
+	Type    servertype.ServerType
+	EndType endtype.EndType
+	Op      string
+	Err     error
+}
+
+func newServerError(s Server, op string, err error) *ServerError {
+	return &ServerError{
+		ID:      s.ID(),
+		Name:    s.Name(),
+		Type:    s.Type(),
+		EndType: s.EndType(),
+		Op:      op,
+		Err:     err,
+	}
+}
+
+func (e *ServerError) Error() string {
+	msg := utils.ToStr(e.EndType.String(), " ", e.Type.String(), " server[", e.Name, "] ", e.Op, " failed")
+	if e.Err != nil {
+		msg = utils.ToStr(msg, ": ", e.Err.Error())
+	}
+	return msg
+}
+
+func (e *ServerError) Unwrap() error {
+	return e.Err
+}
+
+// RunError the application run error, the started servers and sub-applications are rolled back
+type RunError struct {
+	App      string
+	Stage    string
+	Err      error
+	Rollback error
+}
+
+func (e *RunError) Error() string {
+	msg := utils.ToStr("application[", e.App, "] run failed at ", e.Stage)
+	if e.Err != nil {
+		msg = utils.ToStr(msg, ": ", e.Err.Error())
+	}
+	if e.Rollback != nil {
+		msg = utils.ToStr(msg, ", rollback failed: ", e.Rollback.Error())
+	}
+	return msg
+}
+
+func (e *RunError) Unwrap() error {
+	return e.Err
+}
+
+// ServerErrors return the server errors in an aggregated error
+func ServerErrors(err error) (list []*ServerError) {
+	for _, e := range multierr.Errors(err) {
+		var se *ServerError
+		if errors.As(e, &se) {
+			list = append(list, se)
+		}
+	}
+	return
+}
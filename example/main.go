@@ -24,8 +24,9 @@ func main() {
 	r, _ := regCenter.NewEtcdRegister([]string{"127.0.0.1:2379"}, 5*time.Second)
 	app.With(application.Register(r, 5))
 
-	app.Run(func(err error) {
-		panic(err)
-	})
+	if err := app.Run(); err != nil {
+		app.Logger().Error(err.Error())
+		return
+	}
 	app.Wait()
 }
@@ -0,0 +1,6 @@
+package application
+
+// SignalsListening report Wait is listening the signals
+func SignalsListening(app *Application) bool {
+	return app.signalRouter() != nil
+}
@@ -0,0 +1,139 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+)
+
+// State the application readiness state
+type State int32
+
+const (
+	Stopped State = iota
+	Starting
+	Ready
+	Draining
+)
+
+func (s State) String() string {
+	switch s {
+	case Starting:
+		return "starting"
+	case Ready:
+		return "ready"
+	case Draining:
+		return "draining"
+	default:
+		return "stopped"
+	}
+}
+
+// HealthChecker a server can report its health, a nil error means healthy
+type HealthChecker interface {
+	Health(ctx context.Context) error
+}
+
+// ServerHealth the health of a server
+type ServerHealth struct {
+	ID      string `json:"id"`
+	Name    string `json:"name"`
+	Type    string `json:"type"`
+	EndType string `json:"end_type"`
+	Healthy bool   `json:"healthy"`
+	Error   string `json:"error,omitempty"`
+}
+
+// HealthReport the aggregated health of an application and its sub-applications
+type HealthReport struct {
+	App      string          `json:"app"`
+	State    string          `json:"state"`
+	Healthy  bool            `json:"healthy"`
+	Ready    bool            `json:"ready"`
+	Servers  []ServerHealth  `json:"servers"`
+	Children []*HealthReport `json:"children,omitempty"`
+}
+
+// State return the readiness state
+func (app *Application) State() State {
+	return State(atomic.LoadInt32(&app.state))
+}
+
+func (app *Application) setState(s State) {
+	atomic.StoreInt32(&app.state, int32(s))
+}
+
+// Health check the started servers and sub-applications, each check is bounded by the health check timeout, the stopped sub-applications are reported without affecting the health and readiness
+func (app *Application) Health(ctx context.Context) *HealthReport {
+	state := app.State()
+	var list []Server
+	for _, level := range app.startedLevels() {
+		list = append(list, level...)
+	}
+	report := &HealthReport{
+		App:     app.name,
+		State:   state.String(),
+		Healthy: true,
+		Servers: make([]ServerHealth, len(list)),
+	}
+	var wg sync.WaitGroup
+	for i, s := range list {
+		report.Servers[i] = ServerHealth{
+			ID:      s.ID(),
+			Name:    s.Name(),
+			Type:    s.Type().String(),
+			EndType: s.EndType().String(),
+			Healthy: true,
+		}
+		if c, ok := s.(HealthChecker); ok {
+			wg.Add(1)
+			go func(h *ServerHealth, c HealthChecker) {
+				defer wg.Done()
+				if err := app.checkHealth(ctx, c); err != nil {
+					h.Healthy = false
+					h.Error = err.Error()
+				}
+			}(&report.Servers[i], c)
+		}
+	}
+	wg.Wait()
+	for _, h := range report.Servers {
+		if !h.Healthy {
+			report.Healthy = false
+		}
+	}
+	report.Ready = report.Healthy && state == Ready
+	for _, sub := range app.Children() {
+		subReport := sub.Health(ctx)
+		report.Children = append(report.Children, subReport)
+		// a stopped child is reported, but does not affect the parent still serving
+		if sub.State() == Stopped {
+			continue
+		}
+		if !subReport.Healthy {
+			report.Healthy = false
+		}
+		if !subReport.Ready {
+			report.Ready = false
+		}
+	}
+
+	return report
+}
+
+// checkHealth run the check within the health check timeout, a check ignoring the context is abandoned at the deadline
+func (app *Application) checkHealth(ctx context.Context, c HealthChecker) error {
+	ctx, cancel := context.WithTimeout(ctx, app.healthCheckTimeout)
+	defer cancel()
+	done := make(chan error, 1)
+	go func() {
+		done <- c.Health(ctx)
+	}()
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return errors.New("health check timeout, " + ctx.Err().Error())
+	}
+}
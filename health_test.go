@@ -0,0 +1,80 @@
+package application_test
+
+import (
+	"context"
+	"errors"
+	"github.com/obnahsgnaw/application"
+	"github.com/obnahsgnaw/application/service/regCenter"
+	"testing"
+	"time"
+)
+
+type healthServer struct {
+	*testServer
+	health func(ctx context.Context) error
+}
+
+func (s *healthServer) Health(ctx context.Context) error {
+	return s.health(ctx)
+}
+
+type leaderServer struct {
+	*testServer
+}
+
+func (s *leaderServer) LeaderOnly() bool {
+	return true
+}
+
+func TestHealth(t *testing.T) {
+	tests := []struct {
+		name    string
+		health  func(ctx context.Context) error
+		healthy bool
+	}{
+		{name: "healthy", health: func(context.Context) error { return nil }, healthy: true},
+		{name: "unhealthy", health: func(context.Context) error { return errors.New("down") }},
+		{name: "hung check", health: func(context.Context) error { time.Sleep(time.Second); return nil }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := newTestApp(t, application.HealthCheckTimeout(20*time.Millisecond))
+			mustAdd(t, app, &healthServer{testServer: newTestServer("a", &recorder{}), health: tt.health})
+			if err := app.Run(); err != nil {
+				t.Fatal(err)
+			}
+			defer app.Release()
+			start := time.Now()
+			report := app.Health(context.Background())
+			if time.Since(start) > 500*time.Millisecond {
+				t.Error("health check bounded by the timeout need, but not")
+			}
+			if report.Healthy != tt.healthy || report.Ready != tt.healthy {
+				t.Errorf("healthy %v need, but %+v", tt.healthy, report)
+			}
+		})
+	}
+}
+
+func TestHealthFollower(t *testing.T) {
+	reg, _ := regCenter.NewLocalRegister(context.Background())
+	defer reg.Release()
+	leader := newTestApp(t, application.Register(reg, 5))
+	follower := newTestApp(t, application.Register(reg, 5))
+	for _, app := range []*application.Application{leader, follower} {
+		mustAdd(t, app, &leaderServer{newTestServer("job", &recorder{})}, newTestServer("api", &recorder{}))
+	}
+	if err := leader.Run(); err != nil {
+		t.Fatal(err)
+	}
+	defer leader.Release()
+	waitFor(t, "leader elected", leader.IsLeader)
+	if err := follower.Run(); err != nil {
+		t.Fatal(err)
+	}
+	defer follower.Release()
+	report := follower.Health(context.Background())
+	if !report.Ready || len(report.Servers) != 1 || report.Servers[0].ID != "api" {
+		t.Errorf("ready follower with the started api server only need, but %+v", report)
+	}
+}
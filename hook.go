@@ -0,0 +1,73 @@
+package application
+
+import (
+	"context"
+	"go.uber.org/multierr"
+)
+
+// HookStage the application lifecycle stage
+type HookStage string
+
+const (
+	BeforeRun           HookStage = "before-run"
+	AfterServersStarted HookStage = "after-servers-started"
+	BeforeShutdown      HookStage = "before-shutdown"
+	AfterShutdown       HookStage = "after-shutdown"
+	OnServerFailed      HookStage = "on-server-failed"
+	OnChildFailed       HookStage = "on-child-failed"
+)
+
+func (s HookStage) String() string {
+	return string(s)
+}
+
+// HookEvent the hook target, Server and Err are set for OnServerFailed of a start or serving failure, Child and Err for OnChildFailed
+type HookEvent struct {
+	Stage  HookStage
+	App    *Application
+	Server Server
+	Child  *Application
+	Err    error
+}
+
+// Hook a lifecycle hook, errors of BeforeRun and AfterServersStarted hooks abort the run
+type Hook func(ctx context.Context, e *HookEvent) error
+
+type namedHook struct {
+	name string
+	hook Hook
+}
+
+// AddHook add a named hook to the stage, hooks run in the added order
+func (app *Application) AddHook(stage HookStage, name string, hook Hook) {
+	if hook == nil {
+		return
+	}
+	app.hookMu.Lock()
+	defer app.hookMu.Unlock()
+	if app.hooks == nil {
+		app.hooks = make(map[HookStage][]namedHook)
+	}
+	app.hooks[stage] = append(app.hooks[stage], namedHook{name: name, hook: hook})
+}
+
+// runHooks run all hooks of the stage, the failures are returned for the caller to log
+func (app *Application) runHooks(ctx context.Context, e *HookEvent) (err error) {
+	app.hookMu.Lock()
+	hooks := append([]namedHook(nil), app.hooks[e.Stage]...)
+	app.hookMu.Unlock()
+	e.App = app
+	for _, h := range hooks {
+		if hErr := h.hook(ctx, e); hErr != nil {
+			err = multierr.Append(err, app.error(e.Stage.String()+" hook["+h.name+"] failed", hErr))
+		}
+	}
+	return
+}
+
+// runFailureHooks run the OnServerFailed or OnChildFailed hooks, their failures are only logged
+func (app *Application) runFailureHooks(e *HookEvent) {
+	for _, err := range multierr.Errors(app.runHooks(app.ctx, e)) {
+		app.logger.Error(app.prefixedMsg(err.Error()))
+	}
+}
@@ -0,0 +1,60 @@
+package application_test
+
+import (
+	"context"
+	"errors"
+	"github.com/obnahsgnaw/application"
+	"testing"
+)
+
+func TestServerFailedHook(t *testing.T) {
+	tests := []struct {
+		name    string
+		running bool
+	}{
+		{name: "start failure in run"},
+		{name: "start failure of an added server", running: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := newTestApp(t)
+			defer app.Release()
+			var failed []*application.HookEvent
+			app.AddHook(application.OnServerFailed, "record", func(_ context.Context, e *application.HookEvent) error {
+				failed = append(failed, e)
+				return errors.New("hook failed")
+			})
+			s := newTestServer("api", &recorder{})
+			s.startErr = errors.New("start refused")
+			var err error
+			if tt.running {
+				if err = app.Run(); err != nil {
+					t.Fatal(err)
+				}
+				err = app.AddServer(s)
+			} else {
+				mustAdd(t, app, s)
+				err = app.Run()
+			}
+			if err == nil {
+				t.Fatal("start error need, but nil")
+			}
+			if len(failed) != 1 || failed[0].Server != s || !errors.Is(failed[0].Err, s.startErr) {
+				t.Errorf("one failed hook of the server need, but %v", failed)
+			}
+		})
+	}
+}
+
+func TestHookErrorAbortsRun(t *testing.T) {
+	app := newTestApp(t)
+	hookErr := errors.New("not ready")
+	app.AddHook(application.BeforeRun, "check", func(context.Context, *application.HookEvent) error {
+		return hookErr
+	})
+	err := app.Run()
+	var runErr *application.RunError
+	if !errors.As(err, &runErr) || runErr.Stage != application.BeforeRun.String() || !errors.Is(err, hookErr) {
+		t.Errorf("before-run error need, but %v", err)
+	}
+}
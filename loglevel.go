@@ -0,0 +1,63 @@
+package application
+
+import (
+	"github.com/obnahsgnaw/application/pkg/utils"
+	"go.uber.org/zap"
+	"strings"
+)
+
+// logLevelKey the register center key of the log level, the trace level key is suffixed with /trace
+func (app *Application) logLevelKey() string {
+	if app.logLevelWatchKey != "" {
+		return app.logLevelWatchKey
+	}
+	return utils.ToStr(app.cluster.id, "/config/", app.name, "/log-level")
+}
+
+func (app *Application) watchLogLevel() error {
+	if !app.logLevelWatch {
+		return nil
+	}
+	key := app.logLevelKey()
+	traceKey := key + "/trace"
+	app.logger.Info(app.prefixedMsg("log level watching on ", key))
+	return app.register.Watch(app.ctx, key, func(k string, v string, isDel bool) {
+		switch k {
+		case key:
+			app.changeLogLevel(false, v, isDel)
+		case traceKey:
+			app.changeLogLevel(true, v, isDel)
+		}
+	})
+}
+
+// changeLogLevel apply the watched level trimmed of spaces, a deleted key restores the configured level
+func (app *Application) changeLogLevel(trace bool, level string, reset bool) {
+	name := "log level"
+	old := app.logCnf.GetLevel().Level().String()
+	if trace {
+		name = "log trace level"
+		old = app.logCnf.GetTraceLevel().Level().String()
+	}
+	level = strings.TrimSpace(level)
+	if reset {
+		level = app.logCnf.GetLevelString()
+		if trace {
+			level = app.logCnf.GetTraceLevelString()
+		}
+	}
+	if level == old {
+		return
+	}
+	var err error
+	if trace {
+		err = app.logCnf.SetTraceLevel(level)
+	} else {
+		err = app.logCnf.SetLevel(level)
+	}
+	if err != nil {
+		app.logger.Warn(app.prefixedMsg(name, " change rejected"), zap.String("level", level), zap.Error(err))
+		return
+	}
+	app.logger.Info(app.prefixedMsg(name, " changed"), zap.String("from", old), zap.String("to", level))
+}
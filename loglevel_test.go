@@ -0,0 +1,57 @@
+package application_test
+
+import (
+	"context"
+	"github.com/obnahsgnaw/application"
+	"github.com/obnahsgnaw/application/pkg/logging/logger"
+	"github.com/obnahsgnaw/application/service/regCenter"
+	"go.uber.org/zap/zapcore"
+	"testing"
+)
+
+func TestWatchLogLevel(t *testing.T) {
+	const key = "dev/config/test/log-level"
+	local, _ := regCenter.NewLocalRegister(context.Background())
+	defer local.Release()
+	// the initial value is applied on run
+	_ = local.Register(context.Background(), key, "warn", 0)
+	cnf := &logger.Config{Level: "info", TraceLevel: "error"}
+	app := newTestApp(t, application.Register(local, 5), application.Logger(cnf), application.WatchLogLevel(""))
+	defer app.Release()
+	if err := app.Run(); err != nil {
+		t.Fatal(err)
+	}
+	if l := cnf.GetLevel().Level(); l != zapcore.WarnLevel {
+		t.Errorf("initial warn level need, but %s", l)
+	}
+
+	tests := []struct {
+		name      string
+		key       string
+		val       string
+		del       bool
+		wantLevel zapcore.Level
+		wantTrace zapcore.Level
+	}{
+		{name: "trimmed value", key: key, val: "debug\n", wantLevel: zapcore.DebugLevel, wantTrace: zapcore.ErrorLevel},
+		{name: "invalid level rejected", key: key, val: "loud", wantLevel: zapcore.DebugLevel, wantTrace: zapcore.ErrorLevel},
+		{name: "trace level", key: key + "/trace", val: "warn", wantLevel: zapcore.DebugLevel, wantTrace: zapcore.WarnLevel},
+		{name: "reset on delete", key: key, del: true, wantLevel: zapcore.InfoLevel, wantTrace: zapcore.WarnLevel},
+		{name: "trace reset on delete", key: key + "/trace", del: true, wantLevel: zapcore.InfoLevel, wantTrace: zapcore.ErrorLevel},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.del {
+				_ = local.Unregister(context.Background(), tt.key)
+			} else {
+				_ = local.Register(context.Background(), tt.key, tt.val, 0)
+			}
+			if l := cnf.GetLevel().Level(); l != tt.wantLevel {
+				t.Errorf("level %s need, but %s", tt.wantLevel, l)
+			}
+			if l := cnf.GetTraceLevel().Level(); l != tt.wantTrace {
+				t.Errorf("trace level %s need, but %s", tt.wantTrace, l)
+			}
+		})
+	}
+}
@@ -0,0 +1,80 @@
+package application
+
+import (
+	"github.com/obnahsgnaw/application/pkg/locker"
+	"github.com/obnahsgnaw/application/pkg/metrics"
+	"time"
+)
+
+// the built-in metric names
+const (
+	MetricServers            = "app_servers"
+	MetricRegisterTotal      = "app_register_total"
+	MetricEventFiredTotal    = "app_event_fired_total"
+	MetricEventFireSeconds   = "app_event_fire_duration_seconds"
+	MetricLockAcquireTotal   = "app_lock_acquire_total"
+	MetricLockAcquireSeconds = "app_lock_acquire_duration_seconds"
+	metricResultSuccess      = "success"
+	metricResultFailure      = "failure"
+	metricOpRegister         = "register"
+	metricOpUnregister       = "unregister"
+)
+
+type builtinMetrics struct {
+	register    *metrics.Counter
+	eventFired  *metrics.Counter
+	eventFire   *metrics.Histogram
+	lockAcquire *metrics.Counter
+	lockLatency *metrics.Histogram
+}
+
+// Metrics return the metrics registry
+func (app *Application) Metrics() *metrics.Registry {
+	return app.metrics
+}
+
+// initMetrics add the built-in metrics
+func (app *Application) initMetrics() {
+	app.metrics.GaugeFunc(MetricServers, "The servers by server type and end type.", func(set func(v float64, labelValues ...string)) {
+		app.srvMu.RLock()
+		defer app.srvMu.RUnlock()
+		for typ, etServers := range app.servers {
+			for et, servers := range etServers {
+				set(float64(len(servers)), app.name, typ.String(), et.String())
+			}
+		}
+	}, "app", "type", "end_type")
+	app.builtin = &builtinMetrics{
+		register:    app.metrics.Counter(MetricRegisterTotal, "The register center operations by result.", "app", "op", "result"),
+		eventFired:  app.metrics.Counter(MetricEventFiredTotal, "The fired events by topic.", "app", "topic"),
+		eventFire:   app.metrics.Histogram(MetricEventFireSeconds, "The event fire latency by topic.", nil, "app", "topic"),
+		lockAcquire: app.metrics.Counter(MetricLockAcquireTotal, "The lock acquisitions by result.", "app", "result"),
+		lockLatency: app.metrics.Histogram(MetricLockAcquireSeconds, "The lock acquisition latency.", nil, "app", "result"),
+	}
+	app.event.Observe(app.observeEvent)
+}
+
+func (app *Application) observeEvent(topic string, latency time.Duration) {
+	app.builtin.eventFired.Inc(app.name, topic)
+	app.builtin.eventFire.Observe(latency.Seconds(), app.name, topic)
+}
+
+func (app *Application) observeRegister(op string, err error) {
+	result := metricResultSuccess
+	if err != nil {
+		result = metricResultFailure
+	}
+	app.builtin.register.Inc(app.name, op, result)
+}
+
+// ObserveLocker return the locker builder reports the acquisitions to the metrics
+func (app *Application) ObserveLocker(b locker.Builder) locker.Builder {
+	return locker.NewObservedBuilder(b, func(_ string, acquired bool, latency time.Duration) {
+		result := metricResultSuccess
+		if !acquired {
+			result = metricResultFailure
+		}
+		app.builtin.lockAcquire.Inc(app.name, result)
+		app.builtin.lockLatency.Observe(latency.Seconds(), app.name, result)
+	})
+}
@@ -0,0 +1,143 @@
+package application_test
+
+import (
+	"context"
+	"errors"
+	"github.com/obnahsgnaw/application"
+	"github.com/obnahsgnaw/application/pkg/locker"
+	"github.com/obnahsgnaw/application/service/event"
+	"github.com/obnahsgnaw/application/service/regCenter"
+	"strings"
+	"testing"
+	"time"
+)
+
+// metricsText return the exposition of the application registry
+func metricsText(t *testing.T, app *application.Application) string {
+	t.Helper()
+	var b strings.Builder
+	if err := app.Metrics().WriteText(&b); err != nil {
+		t.Fatal(err)
+	}
+	return b.String()
+}
+
+func TestServersMetric(t *testing.T) {
+	app := newTestApp(t)
+	mustAdd(t, app, newTestServer("a", &recorder{}), newTestServer("b", &recorder{}))
+	if text := metricsText(t, app); !strings.Contains(text, `app_servers{app="test",type="rpc",end_type="backend"} 2`) {
+		t.Errorf("2 rpc backend servers need, but\n%s", text)
+	}
+}
+
+func TestRegisterMetric(t *testing.T) {
+	local, _ := regCenter.NewLocalRegister(context.Background())
+	defer local.Release()
+	reg := &failingRegister{LocalRegister: local, failSuffix: "/b"}
+	app := newTestApp(t, application.Register(reg, 5))
+	mustAdd(t, app, newRegServer("auth", &recorder{}, map[string]string{"a": "1"}))
+	if err := app.Run(); err != nil {
+		t.Fatal(err)
+	}
+	_ = app.Release()
+	total := app.Metrics().Counter(application.MetricRegisterTotal, "", "app", "op", "result")
+	if v := total.Value("test", "register", "success"); v != 1 {
+		t.Errorf("1 register need, but %v", v)
+	}
+	if v := total.Value("test", "unregister", "success"); v != 1 {
+		t.Errorf("1 unregister need, but %v", v)
+	}
+
+	failed := newTestApp(t, application.Register(reg, 5))
+	mustAdd(t, failed, newRegServer("auth", &recorder{}, map[string]string{"b": "2"}))
+	if err := failed.Run(); err == nil {
+		t.Fatal("register error need, but nil")
+	}
+	total = failed.Metrics().Counter(application.MetricRegisterTotal, "", "app", "op", "result")
+	if v := total.Value("test", "register", "failure"); v != 1 {
+		t.Errorf("1 failed register need, but %v", v)
+	}
+}
+
+func TestEventMetrics(t *testing.T) {
+	app := newTestApp(t)
+	defer app.Release()
+	app.Event().Register("user.created", func(*event.Event) {})
+	if err := app.Run(); err != nil {
+		t.Fatal(err)
+	}
+	app.Event().NewEvent("user.created", nil).Fire()
+	app.Event().NewEvent("user.created", nil).Fire()
+	fired := app.Metrics().Counter(application.MetricEventFiredTotal, "", "app", "topic")
+	if v := fired.Value("test", "user.created"); v != 2 {
+		t.Errorf("2 fired events need, but %v", v)
+	}
+	latency := app.Metrics().Histogram(application.MetricEventFireSeconds, "", nil, "app", "topic")
+	if n := latency.Count("test", "user.created"); n != 2 {
+		t.Errorf("2 latency observations need, but %d", n)
+	}
+}
+
+type fakeLocker struct{}
+
+func (fakeLocker) Unlock() {}
+
+// fakeLockBuilder acquire the keys except the refused one
+type fakeLockBuilder struct {
+	refused string
+}
+
+func (b fakeLockBuilder) Compete(key string, _ time.Duration) (locker.Locker, error) {
+	if key == b.refused {
+		return nil, errors.New("lock held")
+	}
+	return fakeLocker{}, nil
+}
+
+func TestObserveLocker(t *testing.T) {
+	app := newTestApp(t)
+	b := app.ObserveLocker(fakeLockBuilder{refused: "busy"})
+	if _, err := b.Compete("free", time.Second); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := b.Compete("busy", time.Second); err == nil {
+		t.Fatal("lock error need, but nil")
+	}
+	total := app.Metrics().Counter(application.MetricLockAcquireTotal, "", "app", "result")
+	if total.Value("test", "success") != 1 || total.Value("test", "failure") != 1 {
+		t.Errorf("1 acquired and 1 failed need, but\n%s", metricsText(t, app))
+	}
+	latency := app.Metrics().Histogram(application.MetricLockAcquireSeconds, "", nil, "app", "result")
+	if latency.Count("test", "success") != 1 || latency.Count("test", "failure") != 1 {
+		t.Errorf("2 latency observations need, but\n%s", metricsText(t, app))
+	}
+}
+
+func TestChildSharesMetrics(t *testing.T) {
+	parent := newTestApp(t)
+	defer parent.Release()
+	child := application.New("child", application.DisableSignals())
+	mustAdd(t, child, newTestServer("a", &recorder{}))
+	if err := parent.AddChild(child); err != nil {
+		t.Fatal(err)
+	}
+	if err := parent.Run(); err != nil {
+		t.Fatal(err)
+	}
+	if child.Metrics() != parent.Metrics() {
+		t.Fatal("parent registry need, but another one")
+	}
+	if text := metricsText(t, parent); !strings.Contains(text, `app_servers{app="child",type="rpc",end_type="backend"} 1`) {
+		t.Errorf("child servers in the parent metrics need, but\n%s", text)
+	}
+	// restarted child does not add the collector twice
+	if err := parent.StopChild("child"); err != nil {
+		t.Fatal(err)
+	}
+	if err := parent.StartChild("child"); err != nil {
+		t.Fatal(err)
+	}
+	if text := metricsText(t, parent); strings.Count(text, `app_servers{app="child"`) != 1 {
+		t.Errorf("one child series need, but\n%s", text)
+	}
+}
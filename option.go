@@ -2,10 +2,14 @@ package application
 
 import (
 	"context"
+	"github.com/obnahsgnaw/application/pkg/config"
 	"github.com/obnahsgnaw/application/pkg/debug"
 	"github.com/obnahsgnaw/application/pkg/dynamic"
 	"github.com/obnahsgnaw/application/pkg/logging/logger"
+	"github.com/obnahsgnaw/application/pkg/metrics"
 	"github.com/obnahsgnaw/application/service/regCenter"
+	"go.opentelemetry.io/otel/trace"
+	"time"
 )
 
 type Option func(s *Application)
@@ -55,6 +59,159 @@ func Debug(cb func() bool) Option {
 	return func(s *Application) {
 		if cb != nil {
 			s.debugger = debug.New(dynamic.NewBool(cb))
+			s.cusDebug = true
 		}
 	}
 }
+
+// ShutdownTimeout the deadline of the whole release
+func ShutdownTimeout(timeout time.Duration) Option {
+	return func(s *Application) {
+		if timeout > 0 {
+			s.shutdownTimeout = timeout
+		}
+	}
+}
+
+// HealthCheckTimeout the timeout of each server health check, default 5s
+func HealthCheckTimeout(timeout time.Duration) Option {
+	return func(s *Application) {
+		if timeout > 0 {
+			s.healthCheckTimeout = timeout
+		}
+	}
+}
+
+// ServerReleaseTimeout the deadline of each server release
+func ServerReleaseTimeout(timeout time.Duration) Option {
+	return func(s *Application) {
+		if timeout > 0 {
+			s.serverReleaseTimeout = timeout
+		}
+	}
+}
+
+// Admin listen the admin http endpoint on the addr, such as 127.0.0.1:9100
+func Admin(addr string) Option {
+	return func(s *Application) {
+		s.adminAddr = addr
+	}
+}
+
+// WatchLogLevel watch the log level from the register center, default key: <cluster>/config/<app>/log-level, trace level key: <key>/trace
+func WatchLogLevel(key string) Option {
+	return func(s *Application) {
+		s.logLevelWatch = true
+		s.logLevelWatchKey = key
+	}
+}
+
+// Config wire the cluster, debug, register and logger from the loaded config
+func Config(cnf *AppConfig) Option {
+	return func(s *Application) {
+		if cnf == nil {
+			return
+		}
+		if cnf.Cluster.Id != "" {
+			s.With(CusCluster(NewCluster(cnf.Cluster.Id, cnf.Cluster.Name)))
+		}
+		s.With(Debug(func() bool {
+			return cnf.Debug
+		}))
+		switch cnf.Register.Type {
+		case "", "none":
+			s.With(Register(nil, cnf.Register.Ttl))
+		case "local":
+			r, _ := regCenter.NewLocalRegister(s.ctx)
+			s.With(Register(r, cnf.Register.Ttl))
+			s.AddRelease(r.Release)
+		case "file":
+			if len(cnf.Register.Endpoints) == 0 {
+				panic(s.error("file register path is required", nil))
+			}
+			r, err := regCenter.NewFileRegister(cnf.Register.Endpoints[0], 0)
+			if err != nil {
+				panic(s.error("file register init failed", err))
+			}
+			s.With(Register(r, cnf.Register.Ttl))
+			s.AddRelease(r.Release)
+		case "etcd":
+			r, err := regCenter.NewEtcdRegister(cnf.Register.Endpoints, cnf.Register.Timeout)
+			if err != nil {
+				panic(s.error("etcd register init failed", err))
+			}
+			s.With(Register(r, cnf.Register.Ttl))
+			s.AddRelease(r.Release)
+		default:
+			panic(s.error("register type["+cnf.Register.Type+"] not supported", nil))
+		}
+		if cnf.Log != nil {
+			s.With(Logger(cnf.Log))
+		}
+	}
+}
+
+// WatchConfig watch the config sources, changes are fired as ConfigChangedTopic and ConfigChangedTopic.<source> events
+func WatchConfig(sources ...config.Source) Option {
+	return func(s *Application) {
+		for _, src := range sources {
+			if src != nil {
+				s.configSources = append(s.configSources, src)
+			}
+		}
+	}
+}
+
+// DefaultRestartPolicy the restart policy of the servers not implementing Restartable, default never
+func DefaultRestartPolicy(p RestartPolicy) Option {
+	return func(s *Application) {
+		if p.Mode != "" {
+			s.defRestartPolicy = p
+		}
+	}
+}
+
+// DisableSignals do not listen the os signals in Wait, stop the application by the context or Stop
+func DisableSignals() Option {
+	return func(s *Application) {
+		s.signalEnabled = false
+	}
+}
+
+// LeaderElection the leader election of the leader-only servers, default provided by the register center
+func LeaderElection(e regCenter.Election) Option {
+	return func(s *Application) {
+		s.election = e
+	}
+}
+
+// SingleInstance run as the leader when the register provides no leader election, only for a single instance deployment
+func SingleInstance() Option {
+	return func(s *Application) {
+		s.singleInstance = true
+	}
+}
+
+// Metrics share the metrics registry, default a new registry per application, the sub-applications default the parent's one
+func Metrics(r *metrics.Registry) Option {
+	return func(s *Application) {
+		if r != nil {
+			s.metrics = r
+			s.cusMetrics = true
+		}
+	}
+}
+
+// TracerProvider the tracer provider of the application, propagated to the servers and sub-applications, default the global provider
+func TracerProvider(tp trace.TracerProvider) Option {
+	return func(s *Application) {
+		s.tracerProvider = tp
+	}
+}
+
+// StartupReportFile write the startup summary as json to the file for deployment audits
+func StartupReportFile(path string) Option {
+	return func(s *Application) {
+		s.reportFile = path
+	}
+}
@@ -0,0 +1,117 @@
+package application
+
+import (
+	"errors"
+	"github.com/obnahsgnaw/application/pkg/utils"
+	"sort"
+	"strings"
+)
+
+// Dependent a server depends on other servers, the dependencies are started before it and released after it
+type Dependent interface {
+	DependsOn() []string // server ids
+}
+
+// Prioritized a server with a start phase, lower priority started first, default 0
+type Prioritized interface {
+	Priority() int
+}
+
+func serverPriority(s Server) int {
+	if p, ok := s.(Prioritized); ok {
+		return p.Priority()
+	}
+	return 0
+}
+
+func serverDependencies(s Server) []string {
+	if d, ok := s.(Dependent); ok {
+		return d.DependsOn()
+	}
+	return nil
+}
+
+func serverKey(s Server) string {
+	return utils.ToStr(s.Type().String(), "/", s.EndType().String(), "/", s.ID())
+}
+
+func serverDesc(s Server) string {
+	return utils.ToStr(s.EndType().String(), " ", s.Type().String(), " server[", s.Name(), "]")
+}
+
+// sortServers sort the servers into the start levels, a server only depends on servers in the previous levels
+func sortServers(list []Server) ([][]Server, error) {
+	ids := make(map[string][]string)
+	for _, s := range list {
+		ids[s.ID()] = append(ids[s.ID()], serverKey(s))
+	}
+	sort.SliceStable(list, func(i, j int) bool {
+		pi, pj := serverPriority(list[i]), serverPriority(list[j])
+		if pi != pj {
+			return pi < pj
+		}
+		return serverKey(list[i]) < serverKey(list[j])
+	})
+
+	pending := make(map[string]int)
+	dependents := make(map[string][]string)
+	servers := make(map[string]Server)
+	for _, s := range list {
+		key := serverKey(s)
+		servers[key] = s
+		pending[key] = 0
+		for _, dep := range serverDependencies(s) {
+			depKeys, ok := ids[dep]
+			if !ok {
+				return nil, errors.New(utils.ToStr(serverDesc(s), " depends on unknown server[", dep, "]"))
+			}
+			for _, depKey := range depKeys {
+				if depKey == key {
+					return nil, errors.New(utils.ToStr(serverDesc(s), " depends on itself"))
+				}
+				pending[key]++
+				dependents[depKey] = append(dependents[depKey], key)
+			}
+		}
+	}
+
+	var levels [][]Server
+	started := 0
+	for started < len(list) {
+		var level []Server
+		minPriority := 0
+		for _, s := range list {
+			key := serverKey(s)
+			if n, ok := pending[key]; !ok || n > 0 {
+				continue
+			}
+			p := serverPriority(s)
+			if len(level) == 0 || p < minPriority {
+				level = []Server{s}
+				minPriority = p
+			} else if p == minPriority {
+				level = append(level, s)
+			}
+		}
+		if len(level) == 0 {
+			var cycle []string
+			for _, s := range list {
+				if pending[serverKey(s)] > 0 {
+					cycle = append(cycle, s.ID())
+				}
+			}
+			return nil, errors.New("server dependency cycle detected: " + strings.Join(cycle, ","))
+		}
+		for _, s := range level {
+			key := serverKey(s)
+			delete(pending, key)
+			for _, d := range dependents[key] {
+				pending[d]--
+			}
+		}
+		started += len(level)
+		levels = append(levels, level)
+	}
+
+	return levels, nil
+}
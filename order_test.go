@@ -0,0 +1,91 @@
+package application_test
+
+import (
+	"errors"
+	"github.com/obnahsgnaw/application"
+	"strings"
+	"testing"
+)
+
+func TestServerOrder(t *testing.T) {
+	tests := []struct {
+		name     string
+		deps     map[string][]string
+		priority map[string]int
+		before   [][2]string // the first started before and released after the second
+	}{
+		{
+			name:   "chain",
+			deps:   map[string][]string{"a": nil, "b": {"a"}, "c": {"b"}},
+			before: [][2]string{{"a", "b"}, {"b", "c"}},
+		},
+		{
+			name:   "diamond",
+			deps:   map[string][]string{"db": nil, "cache": {"db"}, "queue": {"db"}, "api": {"cache", "queue"}},
+			before: [][2]string{{"db", "cache"}, {"db", "queue"}, {"cache", "api"}, {"queue", "api"}},
+		},
+		{
+			name:     "priority",
+			deps:     map[string][]string{"late": nil, "early": nil},
+			priority: map[string]int{"late": 10, "early": -10},
+			before:   [][2]string{{"early", "late"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := &recorder{}
+			app := newTestApp(t)
+			for id, deps := range tt.deps {
+				s := newTestServer(id, rec, deps...)
+				s.priority = tt.priority[id]
+				mustAdd(t, app, s)
+			}
+			if err := app.Run(); err != nil {
+				t.Fatal(err)
+			}
+			if err := app.Release(); err != nil {
+				t.Fatal(err)
+			}
+			for _, pair := range tt.before {
+				if rec.index("start:"+pair[0]) > rec.index("start:"+pair[1]) {
+					t.Errorf("%s started before %s need, but %v", pair[0], pair[1], rec.list())
+				}
+				if rec.index("stop:"+pair[0]) < rec.index("stop:"+pair[1]) {
+					t.Errorf("%s released after %s need, but %v", pair[0], pair[1], rec.list())
+				}
+			}
+		})
+	}
+}
+
+func TestServerOrderErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		deps map[string][]string
+		want string
+	}{
+		{name: "cycle", deps: map[string][]string{"a": {"b"}, "b": {"a"}}, want: "cycle"},
+		{name: "self", deps: map[string][]string{"a": {"a"}}, want: "itself"},
+		{name: "unknown", deps: map[string][]string{"a": {"missing"}}, want: "unknown server[missing]"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := &recorder{}
+			app := newTestApp(t)
+			for id, deps := range tt.deps {
+				mustAdd(t, app, newTestServer(id, rec, deps...))
+			}
+			err := app.Run()
+			var runErr *application.RunError
+			if !errors.As(err, &runErr) || runErr.Stage != "sort" {
+				t.Fatalf("sort run error need, but %v", err)
+			}
+			if !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("error contains %q need, but %v", tt.want, err)
+			}
+			if len(rec.list()) != 0 {
+				t.Errorf("no server started need, but %v", rec.list())
+			}
+		})
+	}
+}
@@ -0,0 +1,451 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"github.com/obnahsgnaw/application/pkg/utils"
+	"gopkg.in/yaml.v3"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// 优先级: default tag < 配置文件 < 环境变量 < 命令行参数, 最后校验 required tag
+// 字段标签: json/yaml 配置文件, long 命令行参数及环境变量(大写, - 转 _, 加前缀), env 覆盖环境变量名, default 默认值, required 必填
+
+type loader struct {
+	name      string
+	file      string
+	data      []byte
+	format    string
+	envPrefix string
+	envOn     bool
+	args      []string
+	argsOn    bool
+}
+
+// LoadOption load option
+type LoadOption func(l *loader)
+
+// File load from yaml or json file by the extension
+func File(path string) LoadOption {
+	return func(l *loader) {
+		l.file = path
+	}
+}
+
+// Data load from the yaml or json content, format: yaml, json
+func Data(data []byte, format string) LoadOption {
+	return func(l *loader) {
+		l.data = data
+		l.format = format
+	}
+}
+
+// Env load from environment variables, such as prefix APP: log-dir => APP_LOG_DIR
+func Env(prefix string) LoadOption {
+	return func(l *loader) {
+		l.envOn = true
+		l.envPrefix = prefix
+	}
+}
+
+// Flags load from command-line arguments, such as --log-dir=/tmp
+func Flags(args []string) LoadOption {
+	return func(l *loader) {
+		l.argsOn = true
+		l.args = args
+	}
+}
+
+// Name the flag set name
+func Name(name string) LoadOption {
+	return func(l *loader) {
+		l.name = name
+	}
+}
+
+func configError(msg string, err error) error {
+	return utils.TitledError("config error", msg, err)
+}
+
+// Load the struct pointer from the sources
+func Load(v interface{}, options ...LoadOption) (err error) {
+	l := &loader{name: "config"}
+	for _, o := range options {
+		if o != nil {
+			o(l)
+		}
+	}
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return configError("struct pointer required", nil)
+	}
+	fields, pending := collect(rv.Elem(), "", nil, nil)
+	if err = setDefaults(fields); err != nil {
+		return
+	}
+	if l.file != "" {
+		if l.data, err = os.ReadFile(l.file); err != nil {
+			return configError("read file failed", err)
+		}
+		l.format = strings.TrimPrefix(filepath.Ext(l.file), ".")
+	}
+	if len(l.data) > 0 {
+		// attach the defaulted nested pointers present in the content, so the decoding keeps their defaults
+		probe := reflect.New(rv.Elem().Type())
+		if err = Decode(l.data, l.format, probe.Interface()); err != nil {
+			return configError("decode failed", err)
+		}
+		for _, p := range pending {
+			if pv, err1 := probe.Elem().FieldByIndexErr(p.index); err1 == nil && !pv.IsNil() {
+				p.attach()
+			}
+		}
+		if err = Decode(l.data, l.format, v); err != nil {
+			return configError("decode failed", err)
+		}
+	}
+	// the nil nested pointers are collected again with the defaults, the decoded fields are kept
+	fields, _ = collect(rv.Elem(), "", nil, nil)
+	var detached []field
+	for _, f := range fields {
+		if f.owner != nil {
+			detached = append(detached, f)
+		}
+	}
+	if err = setDefaults(detached); err != nil {
+		return
+	}
+	if l.envOn {
+		for _, f := range fields {
+			name := f.envName(l.envPrefix)
+			if name == "" {
+				continue
+			}
+			if val, ok := os.LookupEnv(name); ok {
+				if err = f.set(val); err != nil {
+					return configError("env "+name+" invalid", err)
+				}
+			}
+		}
+	}
+	if l.argsOn {
+		fs := flag.NewFlagSet(l.name, flag.ContinueOnError)
+		for _, f := range fields {
+			if long := f.field.Tag.Get("long"); long != "" {
+				fs.Var(&fieldFlag{field: f}, long, f.field.Tag.Get("description"))
+			}
+		}
+		if err = fs.Parse(l.args); err != nil {
+			return configError("parse flags failed", err)
+		}
+	}
+
+	return Validate(v)
+}
+
+// Decode the yaml or json content, format: yaml, yml, json. Durations can be strings such as "5s" in both formats
+func Decode(data []byte, format string, v interface{}) error {
+	switch strings.ToLower(format) {
+	case "json":
+		return decodeJson(data, v)
+	case "yaml", "yml", "":
+		return yaml.Unmarshal(data, v)
+	default:
+		return errors.New("unsupported format: " + format)
+	}
+}
+
+// Validate the required tagged fields are not zero
+func Validate(v interface{}) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() == reflect.Ptr {
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		return configError("struct required", nil)
+	}
+	var missing []string
+	fields, _ := collect(rv, "", nil, nil)
+	for _, f := range fields {
+		// the fields of the unset nested pointers are not required
+		if f.owner != nil {
+			continue
+		}
+		if r, _ := strconv.ParseBool(f.field.Tag.Get("required")); r && f.value.IsZero() {
+			missing = append(missing, f.path)
+		}
+	}
+	if len(missing) > 0 {
+		return configError("required fields missing: "+strings.Join(missing, ","), nil)
+	}
+	return nil
+}
+
+type field struct {
+	path  string
+	field reflect.StructField
+	value reflect.Value
+	owner *pendingPtr
+}
+
+// set the value, attach the owner nested pointers
+func (f field) set(s string) error {
+	if err := setValue(f.value, s); err != nil {
+		return err
+	}
+	if f.owner != nil {
+		f.owner.attach()
+	}
+	return nil
+}
+
+// pendingPtr a nil nested struct pointer, its fields are collected in a detached value and attached when one is set
+type pendingPtr struct {
+	index  []int
+	ptr    reflect.Value
+	value  reflect.Value
+	parent *pendingPtr
+}
+
+func (p *pendingPtr) attach() {
+	if p.parent != nil {
+		p.parent.attach()
+	}
+	if p.ptr.IsNil() {
+		p.ptr.Set(p.value)
+	}
+}
+
+func (f field) envName(prefix string) string {
+	if env := f.field.Tag.Get("env"); env != "" {
+		return env
+	}
+	long := f.field.Tag.Get("long")
+	if long == "" {
+		return ""
+	}
+	name := strings.ToUpper(strings.NewReplacer("-", "_", ".", "_").Replace(long))
+	if prefix != "" {
+		name = strings.ToUpper(prefix) + "_" + name
+	}
+	return name
+}
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// collect the leaf fields, the fields of nil nested struct pointers are collected with a pending owner, the pointers are not allocated
+func collect(rv reflect.Value, prefix string, index []int, owner *pendingPtr) (fields []field, pending []*pendingPtr) {
+	rt := rv.Type()
+	for i := 0; i < rt.NumField(); i++ {
+		sf := rt.Field(i)
+		if !sf.IsExported() {
+			continue
+		}
+		fv := rv.Field(i)
+		path := sf.Name
+		if prefix != "" {
+			path = prefix + "." + sf.Name
+		}
+		fIndex := append(append([]int(nil), index...), i)
+		fOwner := owner
+		if sf.Type.Kind() == reflect.Ptr && sf.Type.Elem().Kind() == reflect.Struct {
+			if fv.IsNil() {
+				fOwner = &pendingPtr{index: fIndex, ptr: fv, value: reflect.New(sf.Type.Elem()), parent: owner}
+				pending = append(pending, fOwner)
+				fv = fOwner.value
+			}
+			fv = fv.Elem()
+		}
+		if fv.Kind() == reflect.Struct && fv.Type() != reflect.TypeOf(time.Time{}) {
+			subFields, subPending := collect(fv, path, fIndex, fOwner)
+			fields = append(fields, subFields...)
+			pending = append(pending, subPending...)
+			continue
+		}
+		fields = append(fields, field{path: path, field: sf, value: fv, owner: fOwner})
+	}
+	return
+}
+
+// setDefaults set the default tagged zero fields, the pending pointers are not attached
+func setDefaults(fields []field) error {
+	for _, f := range fields {
+		if d, ok := f.field.Tag.Lookup("default"); ok && d != "" && f.value.IsZero() {
+			if err := setValue(f.value, d); err != nil {
+				return configError("default of "+f.path+" invalid", err)
+			}
+		}
+	}
+	return nil
+}
+
+func setValue(v reflect.Value, s string) error {
+	if v.Type() == durationType {
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return err
+		}
+		v.SetInt(int64(d))
+		return nil
+	}
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(s)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return err
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, err := strconv.ParseInt(s, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetInt(i)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		i, err := strconv.ParseUint(s, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetUint(i)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(s, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetFloat(f)
+	case reflect.Slice:
+		if v.Type().Elem().Kind() != reflect.String {
+			return errors.New("unsupported slice type: " + v.Type().String())
+		}
+		var items []string
+		for _, item := range strings.Split(s, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		v.Set(reflect.ValueOf(items).Convert(v.Type()))
+	default:
+		return errors.New("unsupported type: " + v.Type().String())
+	}
+	return nil
+}
+
+type fieldFlag struct {
+	field field
+}
+
+func (f *fieldFlag) String() string {
+	if !f.field.value.IsValid() {
+		return ""
+	}
+	return utils.ToJson(f.field.value.Interface())
+}
+
+func (f *fieldFlag) Set(s string) error {
+	return f.field.set(s)
+}
+
+func (f *fieldFlag) IsBoolFlag() bool {
+	return f.field.value.IsValid() && f.field.value.Kind() == reflect.Bool
+}
+
+// decodeJson decode the json content, the duration strings are parsed first
+func decodeJson(data []byte, v interface{}) error {
+	d := json.NewDecoder(bytes.NewReader(data))
+	d.UseNumber()
+	var raw interface{}
+	if err := d.Decode(&raw); err != nil {
+		return err
+	}
+	raw, err := jsonDurations(raw, reflect.TypeOf(v))
+	if err != nil {
+		return err
+	}
+	if data, err = json.Marshal(raw); err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+// jsonDurations convert the duration strings of the raw json by the target type
+func jsonDurations(raw interface{}, t reflect.Type) (interface{}, error) {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == durationType {
+		if s, ok := raw.(string); ok {
+			d, err := time.ParseDuration(s)
+			if err != nil {
+				return nil, err
+			}
+			return int64(d), nil
+		}
+		return raw, nil
+	}
+	var err error
+	switch t.Kind() {
+	case reflect.Struct:
+		m, ok := raw.(map[string]interface{})
+		if !ok {
+			return raw, nil
+		}
+		for key, val := range m {
+			if ft, found := jsonFieldType(t, key); found {
+				if m[key], err = jsonDurations(val, ft); err != nil {
+					return nil, errors.New(key + ": " + err.Error())
+				}
+			}
+		}
+	case reflect.Map:
+		if m, ok := raw.(map[string]interface{}); ok {
+			for key, val := range m {
+				if m[key], err = jsonDurations(val, t.Elem()); err != nil {
+					return nil, err
+				}
+			}
+		}
+	case reflect.Slice, reflect.Array:
+		if items, ok := raw.([]interface{}); ok {
+			for i, item := range items {
+				if items[i], err = jsonDurations(item, t.Elem()); err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+	return raw, nil
+}
+
+// jsonFieldType return the type of the field decoded from the json key, matched as encoding/json does
+func jsonFieldType(t reflect.Type, key string) (reflect.Type, bool) {
+	var folded reflect.Type
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if !sf.IsExported() {
+			continue
+		}
+		name := strings.Split(sf.Tag.Get("json"), ",")[0]
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = sf.Name
+		}
+		if name == key {
+			return sf.Type, true
+		}
+		if folded == nil && strings.EqualFold(name, key) {
+			folded = sf.Type
+		}
+	}
+	return folded, folded != nil
+}
@@ -0,0 +1,149 @@
+package config
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+type testLog struct {
+	Dir   string `json:"dir" yaml:"dir" long:"log-dir" required:"false" default:""`
+	Level string `json:"level" yaml:"level" long:"log-level" required:"true" default:"info"`
+}
+
+type testConfig struct {
+	Name      string        `json:"name" yaml:"name" long:"name" required:"true"`
+	Debug     bool          `json:"debug" yaml:"debug" long:"debug" default:"false"`
+	Port      int           `json:"port" yaml:"port" long:"port" default:"80"`
+	Timeout   time.Duration `json:"timeout" yaml:"timeout" long:"timeout" default:"5s"`
+	Endpoints []string      `json:"endpoints" yaml:"endpoints" long:"endpoints" env:"TEST_ENDPOINTS"`
+	Log       *testLog      `json:"log" yaml:"log"`
+}
+
+func TestLoadPrecedence(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.yaml")
+	content := "name: demo\nport: 8080\nlog:\n  dir: /tmp\n  level: warn\n"
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("APP_PORT", "9090")
+	t.Setenv("APP_LOG_LEVEL", "error")
+	t.Setenv("TEST_ENDPOINTS", "a:1, b:2")
+
+	var cnf testConfig
+	err := Load(&cnf, File(path), Env("app"), Flags([]string{"--log-level=debug", "--debug"}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cnf.Name != "demo" {
+		t.Errorf("name=demo need, but %s", cnf.Name)
+	}
+	if cnf.Port != 9090 {
+		t.Errorf("env port=9090 need, but %d", cnf.Port)
+	}
+	if cnf.Timeout != 5*time.Second {
+		t.Errorf("default timeout=5s need, but %s", cnf.Timeout)
+	}
+	if !cnf.Debug {
+		t.Error("flag debug=true need, but false")
+	}
+	if cnf.Log.Dir != "/tmp" || cnf.Log.Level != "debug" {
+		t.Errorf("log dir=/tmp level=debug need, but dir=%s level=%s", cnf.Log.Dir, cnf.Log.Level)
+	}
+	if len(cnf.Endpoints) != 2 || cnf.Endpoints[1] != "b:2" {
+		t.Errorf("endpoints [a:1 b:2] need, but %v", cnf.Endpoints)
+	}
+}
+
+func TestLoadRequired(t *testing.T) {
+	var cnf testConfig
+	if err := Load(&cnf, Data([]byte(`{"port":1}`), "json")); err == nil {
+		t.Error("required name missing error need, but nil")
+	}
+}
+
+func TestFileSourceWatch(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.json")
+	if err := os.WriteFile(path, []byte(`{"name":"demo"}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	changes := make(chan *Change, 1)
+	if err := NewFileSource(path, 10*time.Millisecond).Watch(ctx, func(c *Change) {
+		changes <- c
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(`{"name":"demo2"}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case c := <-changes:
+		var cnf testConfig
+		if err := c.Load(&cnf); err != nil {
+			t.Fatal(err)
+		}
+		if cnf.Name != "demo2" {
+			t.Errorf("name=demo2 need, but %s", cnf.Name)
+		}
+	case <-time.After(time.Second):
+		t.Error("change need, but timeout")
+	}
+}
+
+func TestLoadNestedPointer(t *testing.T) {
+	tests := []struct {
+		name    string
+		options []LoadOption
+		wantLog *testLog
+	}{
+		{name: "unset", options: []LoadOption{Data([]byte(`{"name":"demo"}`), "json")}},
+		{name: "file", options: []LoadOption{Data([]byte("name: demo\nlog:\n  dir: /tmp\n"), "yaml")}, wantLog: &testLog{Dir: "/tmp", Level: "info"}},
+		{name: "flag", options: []LoadOption{Data([]byte(`{"name":"demo"}`), "json"), Flags([]string{"--log-dir=/var"})}, wantLog: &testLog{Dir: "/var", Level: "info"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var cnf testConfig
+			if err := Load(&cnf, tt.options...); err != nil {
+				t.Fatal(err)
+			}
+			if tt.wantLog == nil {
+				if cnf.Log != nil {
+					t.Errorf("nil log need, but %+v", cnf.Log)
+				}
+				return
+			}
+			if cnf.Log == nil || *cnf.Log != *tt.wantLog {
+				t.Errorf("log %+v need, but %+v", tt.wantLog, cnf.Log)
+			}
+		})
+	}
+}
+
+func TestDecodeJsonDuration(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		want    time.Duration
+		wantErr bool
+	}{
+		{name: "string", data: `{"timeout":"5s"}`, want: 5 * time.Second},
+		{name: "nanoseconds", data: `{"timeout":1000}`, want: time.Microsecond},
+		{name: "invalid", data: `{"timeout":"5 apples"}`, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var cnf testConfig
+			err := Decode([]byte(tt.data), "json", &cnf)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error %v need, but %v", tt.wantErr, err)
+			}
+			if cnf.Timeout != tt.want {
+				t.Errorf("timeout %s need, but %s", tt.want, cnf.Timeout)
+			}
+		})
+	}
+}
@@ -0,0 +1,161 @@
+package config
+
+import (
+	"bytes"
+	"context"
+	"github.com/obnahsgnaw/application/pkg/dynamic"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Change a config source change
+type Change struct {
+	Source  string `json:"source"`
+	Key     string `json:"key"`
+	Data    []byte `json:"data"`
+	Deleted bool   `json:"deleted"`
+}
+
+// Format the content format by the key extension, default yaml which also decodes json
+func (c *Change) Format() string {
+	if ext := strings.TrimPrefix(filepath.Ext(c.Key), "."); ext == "json" || ext == "yaml" || ext == "yml" {
+		return ext
+	}
+	return "yaml"
+}
+
+// Load the changed content into the struct pointer with the defaults, then validate
+func (c *Change) Load(v interface{}) error {
+	if c.Deleted {
+		return configError("source deleted", nil)
+	}
+	return Load(v, Data(c.Data, c.Format()))
+}
+
+// Source a watchable config source
+type Source interface {
+	Name() string
+	Watch(ctx context.Context, handler func(*Change)) error
+}
+
+// Reader a config source reads its current content, such as on reload
+type Reader interface {
+	Read(ctx context.Context) ([]*Change, error)
+}
+
+// FileSource poll the file content
+type FileSource struct {
+	path     string
+	interval time.Duration
+}
+
+// NewFileSource return a file source polled every interval
+func NewFileSource(path string, interval time.Duration) *FileSource {
+	if interval <= 0 {
+		interval = 5 * time.Second
+	}
+	return &FileSource{path: path, interval: interval}
+}
+
+func (s *FileSource) Name() string {
+	return "file"
+}
+
+// Watch the content changes until ctx done, the current content is not reported
+func (s *FileSource) Watch(ctx context.Context, handler func(*Change)) error {
+	last, err := os.ReadFile(s.path)
+	if err != nil && !os.IsNotExist(err) {
+		return configError("read file failed", err)
+	}
+	exists := err == nil
+	go func() {
+		ticker := time.NewTicker(s.interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				data, err1 := os.ReadFile(s.path)
+				if err1 != nil {
+					if os.IsNotExist(err1) && exists {
+						exists = false
+						last = nil
+						handler(&Change{Source: s.Name(), Key: s.path, Deleted: true})
+					}
+					continue
+				}
+				if exists && bytes.Equal(data, last) {
+					continue
+				}
+				exists = true
+				last = data
+				handler(&Change{Source: s.Name(), Key: s.path, Data: data})
+			}
+		}
+	}()
+	return nil
+}
+
+// Read the current content, a missing file is reported as deleted
+func (s *FileSource) Read(context.Context) ([]*Change, error) {
+	data, err := os.ReadFile(s.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []*Change{{Source: s.Name(), Key: s.path, Deleted: true}}, nil
+		}
+		return nil, configError("read file failed", err)
+	}
+	return []*Change{{Source: s.Name(), Key: s.path, Data: data}}, nil
+}
+
+// RegisterSource watch the prefixed keys of the register center
+type RegisterSource struct {
+	watcher dynamic.Watcher
+	prefix  string
+}
+
+// NewRegisterSource return a register center source, regCenter.Register implements the watcher
+func NewRegisterSource(watcher dynamic.Watcher, prefix string) *RegisterSource {
+	return &RegisterSource{watcher: watcher, prefix: prefix}
+}
+
+func (s *RegisterSource) Name() string {
+	return "register"
+}
+
+// Watch the prefixed keys, the current values are reported first
+func (s *RegisterSource) Watch(ctx context.Context, handler func(*Change)) error {
+	return s.watcher.Watch(ctx, s.prefix, func(key string, val string, isDel bool) {
+		handler(&Change{Source: s.Name(), Key: key, Data: []byte(val), Deleted: isDel})
+	})
+}
+
+type lister interface {
+	List(ctx context.Context, keyPrefix string) (map[string]string, error)
+}
+
+// Read the current prefixed keys, nothing is read if the watcher can not list
+func (s *RegisterSource) Read(ctx context.Context) ([]*Change, error) {
+	l, ok := s.watcher.(lister)
+	if !ok {
+		return nil, nil
+	}
+	kvs, err := l.List(ctx, s.prefix)
+	if err != nil {
+		return nil, configError("list failed", err)
+	}
+	keys := make([]string, 0, len(kvs))
+	for k := range kvs {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	changes := make([]*Change, 0, len(keys))
+	for _, k := range keys {
+		changes = append(changes, &Change{Source: s.Name(), Key: k, Data: []byte(kvs[k])})
+	}
+	return changes, nil
+}
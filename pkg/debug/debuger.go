@@ -1,22 +1,55 @@
 package debug
 
-import "github.com/obnahsgnaw/application/pkg/dynamic"
+import (
+	"github.com/obnahsgnaw/application/pkg/dynamic"
+	"sync"
+)
 
 type Debugger interface {
 	SetDebug(dynamic.Bool)
 	Debug() bool
 }
 
+// Overrider a debugger can be overridden with a fixed value over its dynamic source
+type Overrider interface {
+	Override(enable *bool)
+	Overridden() bool
+}
+
 type Debug struct {
-	enable dynamic.Bool
+	mu       sync.RWMutex
+	enable   dynamic.Bool
+	override *bool
 }
 
 func New(enable dynamic.Bool) *Debug {
 	return &Debug{enable: enable}
 }
 func (d *Debug) SetDebug(enable dynamic.Bool) {
+	d.mu.Lock()
 	d.enable = enable
+	d.mu.Unlock()
 }
 func (d *Debug) Debug() bool {
-	return d.enable.Val()
+	d.mu.RLock()
+	enable, override := d.enable, d.override
+	d.mu.RUnlock()
+	if override != nil {
+		return *override
+	}
+	return enable.Val()
+}
+
+// Override the dynamic source with the value, a nil value clears the override
+func (d *Debug) Override(enable *bool) {
+	d.mu.Lock()
+	d.override = enable
+	d.mu.Unlock()
+}
+
+// Overridden return the value is overridden
+func (d *Debug) Overridden() bool {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	return d.override != nil
 }
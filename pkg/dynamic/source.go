@@ -0,0 +1,106 @@
+package dynamic
+
+import (
+	"context"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Watcher watch the prefixed keys, regCenter.Register implements it
+type Watcher interface {
+	Watch(ctx context.Context, keyPrefix string, handler func(key string, val string, isDel bool)) error
+}
+
+// ParseBool parse 1,t,true,on,yes,y and 0,f,false,off,no,n case-insensitively
+func ParseBool(s string) (bool, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "on", "yes", "y":
+		return true, nil
+	case "off", "no", "n":
+		return false, nil
+	}
+	return strconv.ParseBool(strings.TrimSpace(s))
+}
+
+// NewWatchedBool return a bool cached from the watched key, invalid value is ignored, deleted key falls back to def
+func NewWatchedBool(ctx context.Context, w Watcher, key string, def bool) (Bool, error) {
+	var val atomic.Bool
+	val.Store(def)
+	err := w.Watch(ctx, key, func(k string, v string, isDel bool) {
+		if k != key {
+			return
+		}
+		if isDel {
+			val.Store(def)
+			return
+		}
+		if b, err := ParseBool(v); err == nil {
+			val.Store(b)
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	return NewBool(val.Load), nil
+}
+
+// NewFileBool return a bool cached from the file content and refreshed every interval until ctx done, missing file falls back to def
+func NewFileBool(ctx context.Context, path string, interval time.Duration, def bool) Bool {
+	var val atomic.Bool
+	if interval <= 0 {
+		interval = 5 * time.Second
+	}
+	load := func() {
+		b := def
+		if content, err := os.ReadFile(path); err == nil {
+			if v, err1 := ParseBool(string(content)); err1 == nil {
+				b = v
+			} else {
+				return
+			}
+		}
+		val.Store(b)
+	}
+	val.Store(def)
+	load()
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				load()
+			}
+		}
+	}()
+	return NewBool(val.Load)
+}
+
+// NewEnvBool return a bool read from the environment variable, the parsed value is cached until the variable changes
+func NewEnvBool(name string, def bool) Bool {
+	var mu sync.Mutex
+	var raw string
+	var set bool
+	val := def
+	return NewBool(func() bool {
+		v, ok := os.LookupEnv(name)
+		mu.Lock()
+		defer mu.Unlock()
+		if ok != set || v != raw {
+			raw, set = v, ok
+			val = def
+			if ok {
+				if b, err := ParseBool(v); err == nil {
+					val = b
+				}
+			}
+		}
+		return val
+	})
+}
@@ -0,0 +1,76 @@
+package dynamic
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+type testWatcher struct {
+	handler func(key string, val string, isDel bool)
+}
+
+func (w *testWatcher) Watch(_ context.Context, _ string, handler func(key string, val string, isDel bool)) error {
+	w.handler = handler
+	return nil
+}
+
+func TestNewWatchedBool(t *testing.T) {
+	w := &testWatcher{}
+	b, err := NewWatchedBool(context.Background(), w, "dev/config/demo/debug", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b.Val() {
+		t.Error("default false need, but true")
+	}
+	w.handler("dev/config/demo/debug", "on", false)
+	if !b.Val() {
+		t.Error("true need after update, but false")
+	}
+	w.handler("dev/config/demo/debug", "invalid", false)
+	if !b.Val() {
+		t.Error("invalid value should be ignored")
+	}
+	w.handler("dev/config/demo/debug-other", "false", false)
+	if !b.Val() {
+		t.Error("other key should be ignored")
+	}
+	w.handler("dev/config/demo/debug", "", true)
+	if b.Val() {
+		t.Error("default false need after delete, but true")
+	}
+}
+
+func TestNewFileBool(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "debug")
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	b := NewFileBool(ctx, path, 10*time.Millisecond, false)
+	if b.Val() {
+		t.Error("default false need for missing file, but true")
+	}
+	if err := os.WriteFile(path, []byte("true\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	deadline := time.Now().Add(time.Second)
+	for !b.Val() && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	if !b.Val() {
+		t.Error("true need after file written, but false")
+	}
+}
+
+func TestNewEnvBool(t *testing.T) {
+	b := NewEnvBool("APPLICATION_TEST_DEBUG", true)
+	if !b.Val() {
+		t.Error("default true need for unset env, but false")
+	}
+	t.Setenv("APPLICATION_TEST_DEBUG", "0")
+	if b.Val() {
+		t.Error("false need after env set, but true")
+	}
+}
@@ -20,3 +20,22 @@ func NewRedisLockBuilder(client *redis.Client) *RedisLockBuilder {
 func (b *RedisLockBuilder) Compete(key string, timeout time.Duration) (Locker, error) {
 	return NewRedisDistributedLocker(b.client, key, timeout)
 }
+
+// ObservedBuilder a builder reports each compete result and latency
+type ObservedBuilder struct {
+	builder  Builder
+	observer func(key string, acquired bool, latency time.Duration)
+}
+
+func NewObservedBuilder(builder Builder, observer func(key string, acquired bool, latency time.Duration)) *ObservedBuilder {
+	return &ObservedBuilder{builder: builder, observer: observer}
+}
+
+func (b *ObservedBuilder) Compete(key string, ttl time.Duration) (Locker, error) {
+	start := time.Now()
+	l, err := b.builder.Compete(key, ttl)
+	if b.observer != nil {
+		b.observer(key, err == nil && l != nil, time.Since(start))
+	}
+	return l, err
+}
@@ -48,7 +48,10 @@ func NewLogger(cnf *Config, debug func() bool) (l *zap.Logger, err error) {
 		jsonEncodeConfig := zap.NewProductionEncoderConfig()
 		jsonEncodeConfig.EncodeTime = zapcore.ISO8601TimeEncoder
 		jsonEncoder := zapcore.NewJSONEncoder(jsonEncodeConfig)
-		ww = zapcore.AddSync(writer.NewFileWriter(filepath.Join(dir, cnf.GetFilename()+".log"), cnf.GetMaxSize(), cnf.GetMaxBackup(), cnf.GetMaxAge(), true))
+		if cnf.files == nil {
+			cnf.files = &writer.Files{}
+		}
+		ww = zapcore.AddSync(cnf.files.Open(filepath.Join(dir, cnf.GetFilename()+".log"), cnf.GetMaxSize(), cnf.GetMaxBackup(), cnf.GetMaxAge(), true))
 		cores = append(cores, zapcore.NewCore(jsonEncoder, ww, cnf.GetLevel()))
 	}
 
@@ -30,6 +30,15 @@ type Config struct {
 	traceLevelInitialized bool
 	subDir                string
 	fileName              string
+	files                 *writer.Files
+}
+
+// ReopenFiles close the log files opened by the loggers of the config and its copies, they are reopened on the next write
+func (c *Config) ReopenFiles() error {
+	if c.files == nil {
+		return nil
+	}
+	return c.files.Reopen()
 }
 
 func (c *Config) GetDir() string {
@@ -344,3 +353,11 @@ func New(name string, cnf *Config, develop bool) (l *zap.Logger, err error) {
 
 	return l, nil
 }
+
+// GetFullDir return the log dir with the sub dir, empty if log to std
+func (c *Config) GetFullDir() string {
+	if c.Dir == "" {
+		return ""
+	}
+	return filepath.Join(c.Dir, c.subDir)
+}
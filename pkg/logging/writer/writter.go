@@ -4,6 +4,7 @@ import (
 	"gopkg.in/natefinch/lumberjack.v2"
 	"io"
 	"os"
+	"sync"
 )
 
 // NewFileWriter Get a writer
@@ -29,6 +30,39 @@ func NewLumberjack(file string, maxSize, maxBackUp, maxAge int, compress bool) *
 	}
 }
 
+// Files the log files opened by a logger, reopened together such as after an external rotation, the zero value is ready to use
+type Files struct {
+	mu    sync.Mutex
+	files map[string]*lumberjack.Logger
+}
+
+// Open return a lumberjack writer of the file, the writer opened before for the file is closed and replaced
+func (f *Files) Open(file string, maxSize, maxBackUp, maxAge int, compress bool) *lumberjack.Logger {
+	l := NewLumberjack(file, maxSize, maxBackUp, maxAge, compress)
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.files == nil {
+		f.files = make(map[string]*lumberjack.Logger)
+	}
+	if old, ok := f.files[file]; ok {
+		_ = old.Close()
+	}
+	f.files[file] = l
+	return l
+}
+
+// Reopen close the opened files, they are reopened by the path on the next write
+func (f *Files) Reopen() (err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, l := range f.files {
+		if closeErr := l.Close(); closeErr != nil && err == nil {
+			err = closeErr
+		}
+	}
+	return
+}
+
 // NewStdWriter std writer
 func NewStdWriter() io.Writer {
 	return os.Stdout
@@ -0,0 +1,47 @@
+package writer
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestReopen(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	files := &Files{}
+	l := files.Open(path, 1, 1, 1, false)
+	defer l.Close()
+	if _, err := l.Write([]byte("before\n")); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Rename(path, path+".1"); err != nil {
+		t.Fatal(err)
+	}
+	if err := files.Reopen(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := l.Write([]byte("after\n")); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("reopened file need, but %v", err)
+	}
+	if string(data) != "after\n" {
+		t.Errorf("after need, but %q", data)
+	}
+}
+
+func TestOpenReplaces(t *testing.T) {
+	dir := t.TempDir()
+	files := &Files{}
+	for i := 0; i < 3; i++ {
+		l := files.Open(filepath.Join(dir, "app.log"), 1, 1, 1, false)
+		_, _ = l.Write([]byte("line\n"))
+	}
+	files.Open(filepath.Join(dir, "app-error.log"), 1, 1, 1, false)
+	if len(files.files) != 2 {
+		t.Errorf("one writer per file need, but %d", len(files.files))
+	}
+	_ = files.Reopen()
+}
@@ -0,0 +1,307 @@
+package metrics
+
+import (
+	"bufio"
+	"github.com/obnahsgnaw/application/pkg/utils"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// DefBuckets the default histogram buckets in seconds
+var DefBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+const (
+	typeCounter   = "counter"
+	typeGauge     = "gauge"
+	typeHistogram = "histogram"
+)
+
+func metricsError(msg string) error {
+	return utils.TitledError("metrics error", msg, nil)
+}
+
+// Registry a metrics registry, the metrics are exposed in the prometheus text format
+type Registry struct {
+	mu      sync.RWMutex
+	metrics map[string]*metric
+}
+
+// NewRegistry return a new registry
+func NewRegistry() *Registry {
+	return &Registry{metrics: make(map[string]*metric)}
+}
+
+type metric struct {
+	name     string
+	help     string
+	typ      string
+	labels   []string
+	buckets  []float64
+	collects []func(set func(v float64, labelValues ...string))
+
+	mu     sync.Mutex
+	series map[string]*series
+}
+
+type series struct {
+	labelValues []string
+	value       float64
+	counts      []uint64
+	count       uint64
+}
+
+// Counter a monotonically increasing metric
+type Counter struct {
+	m *metric
+}
+
+// Gauge a metric can go up and down
+type Gauge struct {
+	m *metric
+}
+
+// Histogram a metric counts the observations in buckets
+type Histogram struct {
+	m *metric
+}
+
+// Counter return the counter of the name, it is created at the first call
+func (r *Registry) Counter(name, help string, labelNames ...string) *Counter {
+	return &Counter{m: r.metric(name, help, typeCounter, labelNames, nil, nil)}
+}
+
+// Gauge return the gauge of the name, it is created at the first call
+func (r *Registry) Gauge(name, help string, labelNames ...string) *Gauge {
+	return &Gauge{m: r.metric(name, help, typeGauge, labelNames, nil, nil)}
+}
+
+// Histogram return the histogram of the name, it is created at the first call, the buckets default DefBuckets
+func (r *Registry) Histogram(name, help string, buckets []float64, labelNames ...string) *Histogram {
+	if len(buckets) == 0 {
+		buckets = DefBuckets
+	}
+	buckets = append([]float64(nil), buckets...)
+	sort.Float64s(buckets)
+	return &Histogram{m: r.metric(name, help, typeHistogram, labelNames, buckets, nil)}
+}
+
+// GaugeFunc add a gauge collected on each exposition, the collector sets the value of each label values, the collectors of the same name are all called
+func (r *Registry) GaugeFunc(name, help string, collect func(set func(v float64, labelValues ...string)), labelNames ...string) {
+	r.metric(name, help, typeGauge, labelNames, nil, collect)
+}
+
+func (r *Registry) metric(name, help, typ string, labels []string, buckets []float64, collect func(set func(v float64, labelValues ...string))) *metric {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if m, ok := r.metrics[name]; ok {
+		if m.typ != typ || len(m.labels) != len(labels) {
+			panic(metricsError("metric[" + name + "] registered with another type or labels"))
+		}
+		if collect != nil {
+			m.collects = append(m.collects, collect)
+		}
+		return m
+	}
+	m := &metric{
+		name:    name,
+		help:    help,
+		typ:     typ,
+		labels:  labels,
+		buckets: buckets,
+		series:  make(map[string]*series),
+	}
+	if collect != nil {
+		m.collects = append(m.collects, collect)
+	}
+	r.metrics[name] = m
+	return m
+}
+
+func (m *metric) with(labelValues []string) *series {
+	if len(labelValues) != len(m.labels) {
+		panic(metricsError("metric[" + m.name + "] need " + strconv.Itoa(len(m.labels)) + " label values"))
+	}
+	key := strings.Join(labelValues, "\xff")
+	s, ok := m.series[key]
+	if !ok {
+		s = &series{labelValues: append([]string(nil), labelValues...)}
+		if m.typ == typeHistogram {
+			s.counts = make([]uint64, len(m.buckets))
+		}
+		m.series[key] = s
+	}
+	return s
+}
+
+// Inc add 1
+func (c *Counter) Inc(labelValues ...string) {
+	c.Add(1, labelValues...)
+}
+
+// Add add the value, negative values are ignored
+func (c *Counter) Add(v float64, labelValues ...string) {
+	if v < 0 {
+		return
+	}
+	c.m.mu.Lock()
+	c.m.with(labelValues).value += v
+	c.m.mu.Unlock()
+}
+
+// Value return the current value
+func (c *Counter) Value(labelValues ...string) float64 {
+	c.m.mu.Lock()
+	defer c.m.mu.Unlock()
+	return c.m.with(labelValues).value
+}
+
+// Set set the value
+func (g *Gauge) Set(v float64, labelValues ...string) {
+	g.m.mu.Lock()
+	g.m.with(labelValues).value = v
+	g.m.mu.Unlock()
+}
+
+// Add add the value, negative to decrease
+func (g *Gauge) Add(v float64, labelValues ...string) {
+	g.m.mu.Lock()
+	g.m.with(labelValues).value += v
+	g.m.mu.Unlock()
+}
+
+// Value return the current value
+func (g *Gauge) Value(labelValues ...string) float64 {
+	g.m.mu.Lock()
+	defer g.m.mu.Unlock()
+	return g.m.with(labelValues).value
+}
+
+// Observe add an observation
+func (h *Histogram) Observe(v float64, labelValues ...string) {
+	h.m.mu.Lock()
+	defer h.m.mu.Unlock()
+	s := h.m.with(labelValues)
+	for i, b := range h.m.buckets {
+		if v <= b {
+			s.counts[i]++
+		}
+	}
+	s.count++
+	s.value += v
+}
+
+// Count return the observation count
+func (h *Histogram) Count(labelValues ...string) uint64 {
+	h.m.mu.Lock()
+	defer h.m.mu.Unlock()
+	return h.m.with(labelValues).count
+}
+
+// Handler return the http handler of the text exposition
+func (r *Registry) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		_ = r.WriteText(w)
+	})
+}
+
+// WriteText write the metrics in the prometheus text exposition format, sorted by name
+func (r *Registry) WriteText(w io.Writer) error {
+	r.mu.RLock()
+	list := make([]*metric, 0, len(r.metrics))
+	for _, m := range r.metrics {
+		list = append(list, m)
+	}
+	r.mu.RUnlock()
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].name < list[j].name
+	})
+	bw := bufio.NewWriter(w)
+	for _, m := range list {
+		m.write(bw)
+	}
+	return bw.Flush()
+}
+
+func (m *metric) write(w *bufio.Writer) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if len(m.collects) > 0 {
+		m.series = make(map[string]*series)
+		for _, collect := range m.collects {
+			collect(func(v float64, labelValues ...string) {
+				if len(labelValues) == len(m.labels) {
+					m.with(labelValues).value = v
+				}
+			})
+		}
+	}
+	if m.help != "" {
+		_, _ = w.WriteString("# HELP " + m.name + " " + escapeHelp(m.help) + "\n")
+	}
+	_, _ = w.WriteString("# TYPE " + m.name + " " + m.typ + "\n")
+	keys := make([]string, 0, len(m.series))
+	for k := range m.series {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		s := m.series[k]
+		if m.typ != typeHistogram {
+			writeSample(w, m.name, m.labels, s.labelValues, "", "", s.value)
+			continue
+		}
+		for i, b := range m.buckets {
+			writeSample(w, m.name+"_bucket", m.labels, s.labelValues, "le", formatFloat(b), float64(s.counts[i]))
+		}
+		writeSample(w, m.name+"_bucket", m.labels, s.labelValues, "le", "+Inf", float64(s.count))
+		writeSample(w, m.name+"_sum", m.labels, s.labelValues, "", "", s.value)
+		writeSample(w, m.name+"_count", m.labels, s.labelValues, "", "", float64(s.count))
+	}
+}
+
+func writeSample(w *bufio.Writer, name string, labels, values []string, extraLabel, extraValue string, v float64) {
+	_, _ = w.WriteString(name)
+	if len(labels) > 0 || extraLabel != "" {
+		_ = w.WriteByte('{')
+		for i, l := range labels {
+			if i > 0 {
+				_ = w.WriteByte(',')
+			}
+			_, _ = w.WriteString(l + `="` + escapeLabel(values[i]) + `"`)
+		}
+		if extraLabel != "" {
+			if len(labels) > 0 {
+				_ = w.WriteByte(',')
+			}
+			_, _ = w.WriteString(extraLabel + `="` + extraValue + `"`)
+		}
+		_ = w.WriteByte('}')
+	}
+	_, _ = w.WriteString(" " + formatFloat(v) + "\n")
+}
+
+func formatFloat(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	case math.IsNaN(v):
+		return "NaN"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+func escapeHelp(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "\n", `\n`).Replace(s)
+}
+
+func escapeLabel(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`).Replace(s)
+}
@@ -0,0 +1,62 @@
+package metrics
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestWriteText(t *testing.T) {
+	r := NewRegistry()
+	c := r.Counter("demo_requests_total", "The requests.", "method")
+	c.Inc("get")
+	c.Add(2, "get")
+	c.Inc("post")
+	r.Gauge("demo_temperature", "").Set(-1.5)
+	h := r.Histogram("demo_latency_seconds", "The latency.", []float64{0.1, 1})
+	h.Observe(0.05)
+	h.Observe(0.5)
+	h.Observe(5)
+	r.GaugeFunc("demo_servers", "The servers.", func(set func(v float64, labelValues ...string)) {
+		set(3, `a"b`)
+	}, "type")
+
+	if c.Value("get") != 3 {
+		t.Errorf("counter=3 need, but %v", c.Value("get"))
+	}
+	var buf bytes.Buffer
+	if err := r.WriteText(&buf); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	for _, line := range []string{
+		`# TYPE demo_requests_total counter`,
+		`demo_requests_total{method="get"} 3`,
+		`demo_requests_total{method="post"} 1`,
+		`demo_temperature -1.5`,
+		`demo_latency_seconds_bucket{le="0.1"} 1`,
+		`demo_latency_seconds_bucket{le="1"} 2`,
+		`demo_latency_seconds_bucket{le="+Inf"} 3`,
+		`demo_latency_seconds_sum 5.55`,
+		`demo_latency_seconds_count 3`,
+		`demo_servers{type="a\"b"} 3`,
+	} {
+		if !strings.Contains(out, line+"\n") {
+			t.Errorf("line %s need, but not found in:\n%s", line, out)
+		}
+	}
+}
+
+func TestRegisterAgain(t *testing.T) {
+	r := NewRegistry()
+	r.Counter("demo_total", "").Inc()
+	if v := r.Counter("demo_total", "").Value(); v != 1 {
+		t.Errorf("same counter need, but value %v", v)
+	}
+	defer func() {
+		if recover() == nil {
+			t.Error("type conflict panic need, but nil")
+		}
+	}()
+	r.Gauge("demo_total", "")
+}
@@ -0,0 +1,161 @@
+package signals
+
+import (
+	"os"
+	"os/signal"
+	"sync"
+)
+
+// Router route the os signals to the handlers, the first shutdown signal starts the shutdown, a second one forces exit
+type Router struct {
+	mu         sync.Mutex
+	handlers   map[os.Signal][]func(os.Signal)
+	shutdown   []os.Signal
+	onShutdown []func(os.Signal)
+	forceExit  func(os.Signal)
+	received   os.Signal
+	ch         chan os.Signal
+	done       chan struct{}
+	stop       chan struct{}
+	started    bool
+}
+
+// NewRouter return a router with the shutdown signals, default: ShutdownSignals
+func NewRouter(shutdown ...os.Signal) *Router {
+	if len(shutdown) == 0 {
+		shutdown = ShutdownSignals()
+	}
+	return &Router{
+		handlers: make(map[os.Signal][]func(os.Signal)),
+		shutdown: shutdown,
+		forceExit: func(os.Signal) {
+			os.Exit(1)
+		},
+		done: make(chan struct{}),
+		stop: make(chan struct{}),
+	}
+}
+
+// Handle add a handler of the non shutdown signals, it runs in its own goroutine
+func (r *Router) Handle(handler func(os.Signal), sig ...os.Signal) {
+	if handler == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, s := range sig {
+		r.handlers[s] = append(r.handlers[s], handler)
+	}
+}
+
+// OnShutdown add a handler called on the first shutdown signal
+func (r *Router) OnShutdown(handler func(os.Signal)) {
+	if handler == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.onShutdown = append(r.onShutdown, handler)
+}
+
+// OnForceExit replace the handler of the second shutdown signal, default os.Exit(1)
+func (r *Router) OnForceExit(handler func(os.Signal)) {
+	if handler == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.forceExit = handler
+}
+
+// Start listen the shutdown signals and the handled signals
+func (r *Router) Start() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.started {
+		return
+	}
+	r.started = true
+	sigs := append([]os.Signal(nil), r.shutdown...)
+	for s := range r.handlers {
+		sigs = append(sigs, s)
+	}
+	r.ch = make(chan os.Signal, 4)
+	signal.Notify(r.ch, sigs...)
+	go func(ch chan os.Signal, stop chan struct{}) {
+		for {
+			select {
+			case <-stop:
+				return
+			case s := <-ch:
+				r.Dispatch(s)
+			}
+		}
+	}(r.ch, r.stop)
+}
+
+// Stop listening, the handlers are kept
+func (r *Router) Stop() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if !r.started {
+		return
+	}
+	r.started = false
+	signal.Stop(r.ch)
+	close(r.stop)
+	r.stop = make(chan struct{})
+}
+
+// Dispatch route a signal as if it was received, the shutdown handlers run in the caller, the other handlers in their own goroutines
+func (r *Router) Dispatch(s os.Signal) {
+	r.mu.Lock()
+	if r.isShutdown(s) {
+		if r.received != nil {
+			forceExit := r.forceExit
+			r.mu.Unlock()
+			forceExit(s)
+			return
+		}
+		r.received = s
+		handlers := append(([]func(os.Signal))(nil), r.onShutdown...)
+		close(r.done)
+		r.mu.Unlock()
+		for _, h := range handlers {
+			h(s)
+		}
+		return
+	}
+	handlers := append(([]func(os.Signal))(nil), r.handlers[s]...)
+	r.mu.Unlock()
+	// a slow handler, such as a reload, does not delay the shutdown signals
+	for _, h := range handlers {
+		go h(s)
+	}
+}
+
+func (r *Router) isShutdown(s os.Signal) bool {
+	for _, s1 := range r.shutdown {
+		if s1 == s {
+			return true
+		}
+	}
+	return false
+}
+
+// Done closed on the first shutdown signal
+func (r *Router) Done() <-chan struct{} {
+	return r.done
+}
+
+// Wait the first shutdown signal
+func (r *Router) Wait() {
+	<-r.done
+}
+
+// Received return the received shutdown signal, nil if not received
+func (r *Router) Received() os.Signal {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.received
+}
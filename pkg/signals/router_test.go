@@ -0,0 +1,90 @@
+//go:build !windows
+
+package signals
+
+import (
+	"os"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestRouterDispatch(t *testing.T) {
+	r := NewRouter(syscall.SIGINT, syscall.SIGTERM)
+	reloaded := make(chan struct{}, 2)
+	r.Handle(func(os.Signal) {
+		reloaded <- struct{}{}
+	}, syscall.SIGHUP)
+	shutdown := 0
+	r.OnShutdown(func(os.Signal) {
+		shutdown++
+	})
+	forced := 0
+	r.OnForceExit(func(os.Signal) {
+		forced++
+	})
+
+	r.Dispatch(syscall.SIGHUP)
+	r.Dispatch(syscall.SIGHUP)
+	for i := 0; i < 2; i++ {
+		select {
+		case <-reloaded:
+		case <-time.After(time.Second):
+			t.Fatalf("2 reloads need, but %d", i)
+		}
+	}
+	select {
+	case <-r.Done():
+		t.Fatal("not done need before shutdown signal")
+	default:
+	}
+
+	r.Dispatch(syscall.SIGTERM)
+	r.Wait()
+	if shutdown != 1 || forced != 0 {
+		t.Errorf("1 shutdown 0 forced need, but %d %d", shutdown, forced)
+	}
+	if r.Received() != syscall.SIGTERM {
+		t.Errorf("SIGTERM received need, but %v", r.Received())
+	}
+
+	r.Dispatch(syscall.SIGINT)
+	if shutdown != 1 || forced != 1 {
+		t.Errorf("1 shutdown 1 forced need, but %d %d", shutdown, forced)
+	}
+}
+
+func TestRouterSlowHandler(t *testing.T) {
+	r := NewRouter(syscall.SIGTERM)
+	release := make(chan struct{})
+	defer close(release)
+	r.Handle(func(os.Signal) {
+		<-release
+	}, syscall.SIGHUP)
+	r.Dispatch(syscall.SIGHUP)
+	r.Dispatch(syscall.SIGTERM)
+	select {
+	case <-r.Done():
+	case <-time.After(time.Second):
+		t.Fatal("shutdown during a slow handler need, but blocked")
+	}
+}
+
+func TestRouterInstances(t *testing.T) {
+	r1 := NewRouter(syscall.SIGUSR2)
+	r2 := NewRouter(syscall.SIGUSR2)
+	r1.Start()
+	defer r1.Stop()
+	r2.Start()
+	defer r2.Stop()
+	if err := syscall.Kill(os.Getpid(), syscall.SIGUSR2); err != nil {
+		t.Fatal(err)
+	}
+	for _, r := range []*Router{r1, r2} {
+		select {
+		case <-r.Done():
+		case <-time.After(time.Second):
+			t.Fatal("both routers should receive the signal")
+		}
+	}
+}
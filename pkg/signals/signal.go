@@ -2,29 +2,37 @@ package signals
 
 import (
 	"os"
-	"os/signal"
-	"syscall"
+	"sync"
 )
 
-var done = make(chan bool, 1)
+var std *Router
+var stdMu sync.Mutex
 
 // Listen Listen os signal, default:syscall.SIGINT  syscall.SIGTERM
+// Deprecated: use a Router instance
 func Listen(cb func(), sig ...os.Signal) {
-	if len(sig) == 0 {
-		sig = append(sig, syscall.SIGINT)
-		sig = append(sig, syscall.SIGTERM)
-	}
-	signalCh := make(chan os.Signal, 1)
-	signal.Notify(signalCh, sig...)
-	go func(signalCh chan os.Signal, done chan bool) {
-		<-signalCh
-		done <- true
-		if cb != nil {
+	r := NewRouter(sig...)
+	if cb != nil {
+		r.OnShutdown(func(os.Signal) {
 			cb()
-		}
-	}(signalCh, done)
+		})
+	}
+	r.Start()
+	stdMu.Lock()
+	if std != nil {
+		std.Stop()
+	}
+	std = r
+	stdMu.Unlock()
 }
 
+// Wait the shutdown signal of the last Listen
+// Deprecated: use a Router instance
 func Wait() {
-	<-done
+	stdMu.Lock()
+	r := std
+	stdMu.Unlock()
+	if r != nil {
+		r.Wait()
+	}
 }
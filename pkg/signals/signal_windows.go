@@ -0,0 +1,23 @@
+//go:build windows
+
+package signals
+
+import (
+	"os"
+	"syscall"
+)
+
+// ShutdownSignals the default shutdown signals: SIGINT, SIGTERM
+func ShutdownSignals() []os.Signal {
+	return []os.Signal{syscall.SIGINT, syscall.SIGTERM}
+}
+
+// ReloadSignals the reload signals: SIGHUP
+func ReloadSignals() []os.Signal {
+	return []os.Signal{syscall.SIGHUP}
+}
+
+// DumpSignals the dump signals, none on windows
+func DumpSignals() []os.Signal {
+	return nil
+}
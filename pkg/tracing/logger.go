@@ -0,0 +1,68 @@
+package tracing
+
+import (
+	"context"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+const (
+	TraceIdKey = "trace_id"
+	SpanIdKey  = "span_id"
+	contextKey = "context"
+)
+
+// Context return a log field carries the context, it is replaced by the trace and span ids by the wrapped core
+func Context(ctx context.Context) zap.Field {
+	return zap.Field{Key: contextKey, Type: zapcore.SkipType, Interface: ctx}
+}
+
+// WrapCore wrap the core to attach the trace and span ids of the logged context field
+func WrapCore(core zapcore.Core) zapcore.Core {
+	if _, ok := core.(*traceCore); ok {
+		return core
+	}
+	return &traceCore{Core: core}
+}
+
+type traceCore struct {
+	zapcore.Core
+}
+
+func (c *traceCore) With(fields []zapcore.Field) zapcore.Core {
+	return &traceCore{Core: c.Core.With(traceFields(fields))}
+}
+
+func (c *traceCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+func (c *traceCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	return c.Core.Write(ent, traceFields(fields))
+}
+
+// traceFields replace the context fields by the trace and span ids
+func traceFields(fields []zapcore.Field) []zapcore.Field {
+	for i, f := range fields {
+		if f.Type != zapcore.SkipType || f.Key != contextKey {
+			continue
+		}
+		ctx, ok := f.Interface.(context.Context)
+		if !ok {
+			continue
+		}
+		sc := trace.SpanContextFromContext(ctx)
+		if !sc.IsValid() {
+			continue
+		}
+		list := make([]zapcore.Field, 0, len(fields)+1)
+		list = append(list, fields[:i]...)
+		list = append(list, zap.String(TraceIdKey, sc.TraceID().String()), zap.String(SpanIdKey, sc.SpanID().String()))
+		return append(list, traceFields(fields[i+1:])...)
+	}
+	return fields
+}
@@ -0,0 +1,37 @@
+package tracing
+
+import (
+	"context"
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type providerKey struct{}
+
+// ContextWithProvider return a context carries the tracer provider
+func ContextWithProvider(ctx context.Context, tp trace.TracerProvider) context.Context {
+	return context.WithValue(ctx, providerKey{}, tp)
+}
+
+// ProviderFromContext return the tracer provider carried by the context, default the global provider
+func ProviderFromContext(ctx context.Context) trace.TracerProvider {
+	if ctx != nil {
+		if tp, ok := ctx.Value(providerKey{}).(trace.TracerProvider); ok && tp != nil {
+			return tp
+		}
+	}
+	return otel.GetTracerProvider()
+}
+
+// Start start a span with the tracer of the context provider
+func Start(ctx context.Context, tracer, span string, opts ...trace.SpanStartOption) (context.Context, trace.Span) {
+	return ProviderFromContext(ctx).Tracer(tracer).Start(ctx, span, opts...)
+}
+
+// NewInMemoryProvider return a synchronous tracer provider records the ended spans in memory, for tests
+func NewInMemoryProvider() (*sdktrace.TracerProvider, *tracetest.InMemoryExporter) {
+	exporter := tracetest.NewInMemoryExporter()
+	return sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter)), exporter
+}
@@ -0,0 +1,38 @@
+package tracing
+
+import (
+	"context"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+	"testing"
+)
+
+func TestLoggerTraceIds(t *testing.T) {
+	tp, exporter := NewInMemoryProvider()
+	ctx := ContextWithProvider(context.Background(), tp)
+	ctx, span := Start(ctx, "test", "op")
+
+	core, logs := observer.New(zap.DebugLevel)
+	l := zap.New(core, zap.WrapCore(WrapCore))
+	l.Info("traced", Context(ctx), zap.String("k", "v"))
+	l.Info("untraced", Context(context.Background()))
+	span.End()
+
+	entries := logs.All()
+	if len(entries) != 2 {
+		t.Fatalf("2 entries need, but %d", len(entries))
+	}
+	fields := entries[0].ContextMap()
+	if fields[TraceIdKey] != span.SpanContext().TraceID().String() || fields[SpanIdKey] != span.SpanContext().SpanID().String() {
+		t.Errorf("trace and span ids need, but %v", fields)
+	}
+	if fields["k"] != "v" {
+		t.Errorf("field k=v need, but %v", fields)
+	}
+	if _, ok := entries[1].ContextMap()[TraceIdKey]; ok {
+		t.Error("no trace id need for the context without span")
+	}
+	if spans := exporter.GetSpans(); len(spans) != 1 || spans[0].Name != "op" {
+		t.Errorf("span op need, but %v", spans)
+	}
+}
@@ -0,0 +1,118 @@
+package application
+
+import (
+	"context"
+	"github.com/obnahsgnaw/application/service/regCenter"
+	"go.uber.org/multierr"
+	"time"
+)
+
+type registration struct {
+	server Server
+	info   *regCenter.RegInfo
+}
+
+// Registrable a server registered to the register center after started, and unregistered before released
+type Registrable interface {
+	RegInfo() *regCenter.RegInfo
+}
+
+func (app *Application) registerServer(s Server) error {
+	r, ok := s.(Registrable)
+	if !ok {
+		return nil
+	}
+	info := copyRegInfo(r.RegInfo())
+	if info == nil {
+		return nil
+	}
+	if info.AppId == "" {
+		info.AppId = app.cluster.id
+	}
+	if info.Ttl <= 0 {
+		info.Ttl = app.regTtl
+	}
+	if info.Instance != nil {
+		if info.Instance.StartedAt == nil {
+			now := time.Now()
+			info.Instance.StartedAt = &now
+		}
+		if c, ok := s.(HealthChecker); ok {
+			info.Instance.Health = regCenter.InstanceHealthy
+			if app.checkHealth(app.ctx, c) != nil {
+				info.Instance.Health = regCenter.InstanceUnhealthy
+			}
+		}
+	}
+	if err := app.DoRegister(info, app.regLog); err != nil {
+		// the keys written before the failure are removed, a partial registration is not kept
+		ctx, cancel := context.WithTimeout(context.Background(), app.shutdownTimeout)
+		defer cancel()
+		for k := range info.Kvs() {
+			err = multierr.Append(err, app.register.Unregister(ctx, k))
+		}
+		return newServerError(s, "register", err)
+	}
+	app.regMu.Lock()
+	app.registered[serverKey(s)] = &registration{server: s, info: info}
+	app.regMu.Unlock()
+	return nil
+}
+
+// copyRegInfo copy the register info of the server, the server's one is not modified
+func copyRegInfo(info *regCenter.RegInfo) *regCenter.RegInfo {
+	if info == nil {
+		return nil
+	}
+	c := *info
+	if info.Instance != nil {
+		instance := *info.Instance
+		c.Instance = &instance
+	}
+	return &c
+}
+
+func (app *Application) unregisterServer(ctx context.Context, s Server) error {
+	key := serverKey(s)
+	app.regMu.Lock()
+	reg, ok := app.registered[key]
+	delete(app.registered, key)
+	app.regMu.Unlock()
+	if !ok {
+		return nil
+	}
+	if err := app.doUnregister(ctx, reg.info, app.regLog); err != nil {
+		return newServerError(s, "unregister", err)
+	}
+	return nil
+}
+
+func (app *Application) unregisterServers(ctx context.Context) (err error) {
+	app.regMu.RLock()
+	var list []Server
+	for _, reg := range app.registered {
+		list = append(list, reg.server)
+	}
+	app.regMu.RUnlock()
+	for _, s := range list {
+		err = multierr.Append(err, app.unregisterServer(ctx, s))
+	}
+	return
+}
+
+func (app *Application) regLog(msg string) {
+	app.logger.Debug(app.prefixedMsg(msg))
+}
+
+// RegisteredKvs return the register center entries registered by the servers
+func (app *Application) RegisteredKvs() map[string]string {
+	kvs := make(map[string]string)
+	app.regMu.RLock()
+	defer app.regMu.RUnlock()
+	for _, reg := range app.registered {
+		for k, v := range reg.info.Kvs() {
+			kvs[k] = v
+		}
+	}
+	return kvs
+}
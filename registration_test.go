@@ -0,0 +1,130 @@
+package application_test
+
+import (
+	"context"
+	"errors"
+	"github.com/obnahsgnaw/application"
+	"github.com/obnahsgnaw/application/regtype"
+	"github.com/obnahsgnaw/application/service/regCenter"
+	"strings"
+	"testing"
+)
+
+// failingRegister fail the register of the keys with the suffix
+type failingRegister struct {
+	*regCenter.LocalRegister
+	failSuffix string
+}
+
+func (r *failingRegister) Register(ctx context.Context, key, val string, ttl int64) error {
+	if strings.HasSuffix(key, r.failSuffix) {
+		return errors.New("register refused")
+	}
+	return r.LocalRegister.Register(ctx, key, val, ttl)
+}
+
+type regServer struct {
+	*testServer
+	info *regCenter.RegInfo
+}
+
+func (s *regServer) RegInfo() *regCenter.RegInfo {
+	return s.info
+}
+
+func newRegServer(id string, rec *recorder, values map[string]string) *regServer {
+	return &regServer{
+		testServer: newTestServer(id, rec),
+		info: &regCenter.RegInfo{
+			RegType:    regtype.Rpc,
+			ServerInfo: regCenter.ServerInfo{Id: id, Type: "api", EndType: "backend"},
+			Host:       "127.0.0.1:80",
+			Values:     values,
+		},
+	}
+}
+
+func TestRegisterServer(t *testing.T) {
+	tests := []struct {
+		name       string
+		failSuffix string
+		wantErr    bool
+	}{
+		{name: "registered", failSuffix: "-none-"},
+		{name: "partial failure", failSuffix: "/b", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			local, _ := regCenter.NewLocalRegister(context.Background())
+			defer local.Release()
+			reg := &failingRegister{LocalRegister: local, failSuffix: tt.failSuffix}
+			app := newTestApp(t, application.Register(reg, 5))
+			s := newRegServer("auth", &recorder{}, map[string]string{"a": "1", "b": "2", "c": "3"})
+			mustAdd(t, app, s)
+			err := app.Run()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error %v need, but %v", tt.wantErr, err)
+			}
+			if s.info.AppId != "" || s.info.Ttl != 0 {
+				t.Errorf("server reg info not modified need, but app id %q ttl %d", s.info.AppId, s.info.Ttl)
+			}
+			index, _ := local.LastPrefixedIndex(context.Background(), "dev/", func(string) int { return 1 })
+			if tt.wantErr && index != -1 {
+				t.Error("partial keys removed need, but keys left")
+			}
+			if !tt.wantErr && len(app.RegisteredKvs()) != 3 {
+				t.Errorf("3 registered keys need, but %v", app.RegisteredKvs())
+			}
+			_ = app.Release()
+			if index, _ = local.LastPrefixedIndex(context.Background(), "dev/", func(string) int { return 1 }); index != -1 {
+				t.Error("keys unregistered after release need, but keys left")
+			}
+		})
+	}
+}
+
+type healthRegServer struct {
+	*regServer
+	err error
+}
+
+func (s *healthRegServer) Health(context.Context) error {
+	return s.err
+}
+
+func TestRegisterInstanceHealth(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want string
+	}{
+		{name: "healthy", want: regCenter.InstanceHealthy},
+		{name: "unhealthy", err: errors.New("db down"), want: regCenter.InstanceUnhealthy},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			local, _ := regCenter.NewLocalRegister(context.Background())
+			defer local.Release()
+			app := newTestApp(t, application.Register(local, 5))
+			defer app.Release()
+			s := &healthRegServer{regServer: newRegServer("auth", &recorder{}, nil), err: tt.err}
+			s.info.Instance = &regCenter.ServiceInstance{Version: "v1"}
+			mustAdd(t, app, s)
+			if err := app.Run(); err != nil {
+				t.Fatal(err)
+			}
+			kvs := app.RegisteredKvs()
+			if len(kvs) != 1 {
+				t.Fatalf("one instance key need, but %v", kvs)
+			}
+			for _, v := range kvs {
+				if instance, err := regCenter.ParseInstance(v); err != nil || instance.Health != tt.want {
+					t.Errorf("health %s need, but %s %v", tt.want, v, err)
+				}
+			}
+			if s.info.Instance.Health != "" {
+				t.Error("server instance not modified need, but health set")
+			}
+		})
+	}
+}
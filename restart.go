@@ -0,0 +1,169 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"go.uber.org/zap"
+	"math"
+	"strconv"
+	"time"
+)
+
+// RestartMode the server restart mode
+type RestartMode string
+
+const (
+	RestartNever     RestartMode = "never"
+	RestartOnFailure RestartMode = "on-failure"
+	RestartAlways    RestartMode = "always"
+)
+
+// server restart event topics, the event data is the server and the attempt
+const (
+	ServerRestartingTopic = "server.restarting"
+	ServerRestartedTopic  = "server.restarted"
+	ServerGaveUpTopic     = "server.gave-up"
+)
+
+// RestartPolicy the server restart policy, the backoff doubles on each attempt up to the max backoff,
+// the attempts are reset when a restarted server stayed up for the reset duration
+type RestartPolicy struct {
+	Mode           RestartMode
+	MaxRetries     int // <= 0 unlimited
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+	ResetAfter     time.Duration // <= 0 the max backoff
+}
+
+// Restartable a server with its own restart policy
+type Restartable interface {
+	RestartPolicy() RestartPolicy
+}
+
+func (p RestartPolicy) backoff(attempt int) time.Duration {
+	d := p.InitialBackoff
+	if d <= 0 {
+		d = time.Second
+	}
+	max := p.MaxBackoff
+	if max <= 0 {
+		max = time.Minute
+	}
+	for i := 1; i < attempt && d < max; i++ {
+		d *= 2
+	}
+	if d > max {
+		d = max
+	}
+	return d
+}
+
+func (p RestartPolicy) resetAfter() time.Duration {
+	if p.ResetAfter > 0 {
+		return p.ResetAfter
+	}
+	return p.backoff(math.MaxInt32)
+}
+
+func (app *Application) restartPolicy(s Server) RestartPolicy {
+	if r, ok := s.(Restartable); ok {
+		return r.RestartPolicy()
+	}
+	return app.defRestartPolicy
+}
+
+func (app *Application) markStopping(s Server, stopping bool) {
+	app.restartMu.Lock()
+	defer app.restartMu.Unlock()
+	if stopping {
+		app.stopping[serverKey(s)] = true
+	} else {
+		delete(app.stopping, serverKey(s))
+	}
+}
+
+// serverExited handle the exit reported by a started server, restart it by the policy
+func (app *Application) serverExited(s Server, err error) {
+	key := serverKey(s)
+	app.restartMu.Lock()
+	stopping := app.stopping[key]
+	app.restartMu.Unlock()
+	if stopping || app.State() == Draining || app.State() == Stopped {
+		return
+	}
+	if err != nil {
+		app.serverFailed(s, err)
+	} else {
+		app.logger.Warn(app.prefixedMsg(serverDesc(s), " exited"))
+	}
+	policy := app.restartPolicy(s)
+	if policy.Mode != RestartAlways && (policy.Mode != RestartOnFailure || err == nil) {
+		if err != nil {
+			app.fatal(newServerError(s, "serve", err))
+		}
+		return
+	}
+	app.restartMu.Lock()
+	if at, ok := app.restartedAt[key]; ok && time.Since(at) >= policy.resetAfter() {
+		app.restarts[key] = 0
+	}
+	delete(app.restartedAt, key)
+	app.restarts[key]++
+	attempt := app.restarts[key]
+	app.restartMu.Unlock()
+	if policy.MaxRetries > 0 && attempt > policy.MaxRetries {
+		gaveUp := newServerError(s, "restart", errors.New("gave up after "+strconv.Itoa(policy.MaxRetries)+" attempts"))
+		app.fireRestartEvent(ServerGaveUpTopic, s, attempt-1)
+		app.serverGaveUp(s, gaveUp)
+		return
+	}
+	backoff := policy.backoff(attempt)
+	app.logger.Warn(app.prefixedMsg(serverDesc(s), " restarting"), zap.Int("attempt", attempt), zap.Duration("backoff", backoff))
+	app.fireRestartEvent(ServerRestartingTopic, s, attempt)
+	go app.restartServer(s, attempt, backoff)
+}
+
+func (app *Application) restartServer(s Server, attempt int, backoff time.Duration) {
+	select {
+	case <-app.ctx.Done():
+		return
+	case <-time.After(backoff):
+	}
+	if !app.isLive(s) {
+		return
+	}
+	if err := app.releaseServer(app.ctx, s); err != nil {
+		app.logger.Warn(app.prefixedMsg(serverDesc(s), " release before restart failed"), zap.Error(err))
+	}
+	if err := app.startServer(app.ctx, s); err != nil {
+		app.serverExited(s, err)
+		return
+	}
+	// released or removed while restarting
+	if !app.isLive(s) {
+		ctx, cancel := context.WithTimeout(context.Background(), app.shutdownTimeout)
+		defer cancel()
+		if err := app.releaseServer(ctx, s); err != nil {
+			app.logger.Warn(app.prefixedMsg(serverDesc(s), " release after restart failed"), zap.Error(err))
+		}
+		return
+	}
+	app.restartMu.Lock()
+	app.restartedAt[serverKey(s)] = time.Now()
+	app.restartMu.Unlock()
+	app.logger.Info(app.prefixedMsg(serverDesc(s), " restarted"), zap.Int("attempt", attempt))
+	app.fireRestartEvent(ServerRestartedTopic, s, attempt)
+}
+
+func (app *Application) fireRestartEvent(topic string, s Server, attempt int) {
+	app.event.NewEvent(topic, []interface{}{s, attempt}).Fire()
+}
+
+// serverGaveUp the restart budget of the server exhausted
+func (app *Application) serverGaveUp(s Server, err error) {
+	app.errMu.Lock()
+	app.errs = append(app.errs, err)
+	app.errMu.Unlock()
+	app.logger.Error(app.prefixedMsg(err.Error()), zap.String("server_id", s.ID()))
+	app.fatal(err)
+}
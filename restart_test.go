@@ -0,0 +1,122 @@
+package application_test
+
+import (
+	"errors"
+	"github.com/obnahsgnaw/application"
+	"github.com/obnahsgnaw/application/endtype"
+	"github.com/obnahsgnaw/application/servertype"
+	"sync"
+	"testing"
+	"time"
+)
+
+// count return the times of the recorded event
+func (r *recorder) count(ev string) (n int) {
+	for _, e := range r.list() {
+		if e == ev {
+			n++
+		}
+	}
+	return
+}
+
+// callbackServer a callback server keeps its report callback
+type callbackServer struct {
+	rec *recorder
+	mu  sync.Mutex
+	cb  func(error)
+}
+
+func (s *callbackServer) ID() string                  { return "cb" }
+func (s *callbackServer) Name() string                { return "cb" }
+func (s *callbackServer) Type() servertype.ServerType { return servertype.Rpc }
+func (s *callbackServer) EndType() endtype.EndType    { return endtype.Backend }
+func (s *callbackServer) Release()                    { s.rec.add("stop:cb") }
+
+func (s *callbackServer) Run(cb func(error)) {
+	s.mu.Lock()
+	s.cb = cb
+	s.mu.Unlock()
+	s.rec.add("start:cb")
+}
+
+func (s *callbackServer) report(err error) {
+	s.mu.Lock()
+	cb := s.cb
+	s.mu.Unlock()
+	cb(err)
+}
+
+func TestCallbackServerNilReport(t *testing.T) {
+	rec := &recorder{}
+	app := newTestApp(t, application.DefaultRestartPolicy(application.RestartPolicy{Mode: application.RestartAlways, InitialBackoff: time.Millisecond}))
+	defer app.Release()
+	s := &callbackServer{rec: rec}
+	mustAdd(t, app, s)
+	if err := app.Run(); err != nil {
+		t.Fatal(err)
+	}
+	s.report(nil)
+	s.report(errors.New("crashed"))
+	waitFor(t, "restart after the failure", func() bool { return rec.count("start:cb") == 2 })
+	if rec.count("stop:cb") != 1 {
+		t.Errorf("restarted once need, but %v", rec.list())
+	}
+}
+
+func TestRestartAttemptsReset(t *testing.T) {
+	tests := []struct {
+		name       string
+		resetAfter time.Duration
+		stayUp     time.Duration
+		wantStarts int
+	}{
+		{name: "crash loop gives up", resetAfter: time.Hour, wantStarts: 2},
+		{name: "stayed up resets", resetAfter: 10 * time.Millisecond, stayUp: 20 * time.Millisecond, wantStarts: 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := &recorder{}
+			app := newTestApp(t, application.DefaultRestartPolicy(application.RestartPolicy{
+				Mode:           application.RestartOnFailure,
+				MaxRetries:     1,
+				InitialBackoff: time.Millisecond,
+				ResetAfter:     tt.resetAfter,
+			}))
+			defer app.Release()
+			s := newTestServer("api", rec)
+			mustAdd(t, app, s)
+			if err := app.Run(); err != nil {
+				t.Fatal(err)
+			}
+			s.exited <- errors.New("crashed")
+			waitFor(t, "first restart", func() bool { return rec.count("start:api") == 2 })
+			waitFor(t, "restart recorded", func() bool { return len(app.Errors()) == 1 })
+			time.Sleep(tt.stayUp)
+			s.exited <- errors.New("crashed again")
+			waitFor(t, "second exit handled", func() bool { return len(app.Errors()) >= 2 })
+			if tt.wantStarts == 3 {
+				waitFor(t, "second restart", func() bool { return rec.count("start:api") == 3 })
+			} else if len(app.Errors()) != 3 {
+				t.Errorf("gave up error need, but %v", app.Errors())
+			}
+		})
+	}
+}
+
+func TestNoRestartAfterRelease(t *testing.T) {
+	rec := &recorder{}
+	app := newTestApp(t, application.DefaultRestartPolicy(application.RestartPolicy{Mode: application.RestartAlways, InitialBackoff: 20 * time.Millisecond}))
+	s := newTestServer("api", rec)
+	mustAdd(t, app, s)
+	if err := app.Run(); err != nil {
+		t.Fatal(err)
+	}
+	s.exited <- errors.New("crashed")
+	waitFor(t, "exit handled", func() bool { return len(app.Errors()) == 1 })
+	_ = app.Release()
+	time.Sleep(40 * time.Millisecond)
+	if rec.count("start:api") != 1 {
+		t.Errorf("not restarted after release need, but %v", rec.list())
+	}
+}
@@ -0,0 +1,140 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"github.com/obnahsgnaw/application/endtype"
+	"github.com/obnahsgnaw/application/servertype"
+	"go.uber.org/multierr"
+	"go.uber.org/zap"
+	"sync"
+)
+
+// Server identify a server, it should implement CallbackServer or LifecycleServer
+type Server interface {
+	ID() string
+	Name() string
+	Type() servertype.ServerType
+	EndType() endtype.EndType
+}
+
+// CallbackServer the callback style server, errors reported while Run abort the boot, later errors are server exits, nil reports are ignored
+type CallbackServer interface {
+	Server
+	Run(func(error))
+	Release()
+}
+
+// LifecycleServer the error returning server, Start should return after the server is serving
+type LifecycleServer interface {
+	Server
+	Start(ctx context.Context) error
+	Stop(ctx context.Context) error
+}
+
+// Exiter a lifecycle server reports its exit after started, a nil error means a clean exit
+type Exiter interface {
+	Exited() <-chan error
+}
+
+// validServer check the server implements a lifecycle
+func validServer(s Server) error {
+	switch s.(type) {
+	case LifecycleServer, CallbackServer:
+		return nil
+	}
+	return newServerError(s, "add", errors.New("neither CallbackServer nor LifecycleServer implemented"))
+}
+
+func (app *Application) startServer(ctx context.Context, s Server) (err error) {
+	app.markStopping(s, false)
+	ctx = app.traceServer(ctx, s)
+	switch v := s.(type) {
+	case LifecycleServer:
+		if err = v.Start(ctx); err == nil {
+			if e, ok := s.(Exiter); ok {
+				go func() {
+					select {
+					case exitErr := <-e.Exited():
+						app.serverExited(s, exitErr)
+					case <-ctx.Done():
+					}
+				}()
+			}
+		}
+	case CallbackServer:
+		var mu sync.Mutex
+		booting := true
+		v.Run(func(err1 error) {
+			if err1 == nil {
+				return
+			}
+			mu.Lock()
+			if booting {
+				err = multierr.Append(err, err1)
+				mu.Unlock()
+				return
+			}
+			mu.Unlock()
+			app.serverExited(s, err1)
+		})
+		mu.Lock()
+		booting = false
+		mu.Unlock()
+	default:
+		err = errors.New("no lifecycle implemented")
+	}
+	if err != nil {
+		return newServerError(s, "start", err)
+	}
+	return nil
+}
+
+func (app *Application) stopServer(ctx context.Context, s Server) error {
+	switch v := s.(type) {
+	case LifecycleServer:
+		return v.Stop(ctx)
+	case GracefulReleaser:
+		return v.GracefulRelease(ctx)
+	case CallbackServer:
+		v.Release()
+	}
+	return nil
+}
+
+// serverFailed handle the failure reported by a started server
+func (app *Application) serverFailed(s Server, err error) {
+	err = newServerError(s, "serve", err)
+	app.errMu.Lock()
+	app.errs = append(app.errs, err)
+	app.errMu.Unlock()
+	app.logger.Error(app.prefixedMsg(err.Error()), zap.String("server_id", s.ID()))
+	app.runFailureHooks(&HookEvent{Stage: OnServerFailed, Server: s, Err: err})
+	if app.parent != nil {
+		app.parent.childFailed(app.name, err)
+	}
+}
+
+// Errors return the failures reported by the started servers
+func (app *Application) Errors() []error {
+	app.errMu.Lock()
+	defer app.errMu.Unlock()
+	return append([]error(nil), app.errs...)
+}
+
+// rollback release the started servers and children after a boot failure
+func (app *Application) rollback(levels [][]Server, children []*Application) (err error) {
+	ctx, cancel := context.WithTimeout(context.Background(), app.shutdownTimeout)
+	defer cancel()
+	for i := len(children) - 1; i >= 0; i-- {
+		if subErr := children[i].release(ctx); subErr != nil {
+			err = multierr.Append(err, app.error("sub-application["+children[i].name+"] release failed", subErr))
+		}
+	}
+	err = multierr.Append(err, app.unregisterServers(ctx))
+	for i := len(levels) - 1; i >= 0; i-- {
+		err = multierr.Append(err, app.releaseLevel(ctx, levels[i]))
+	}
+	app.setLevels([][]Server{})
+	return
+}
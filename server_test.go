@@ -0,0 +1,131 @@
+package application_test
+
+import (
+	"context"
+	"github.com/obnahsgnaw/application"
+	"github.com/obnahsgnaw/application/endtype"
+	"github.com/obnahsgnaw/application/servertype"
+	"testing"
+)
+
+type bareServer struct{}
+
+func (bareServer) ID() string                  { return "bare" }
+func (bareServer) Name() string                { return "bare" }
+func (bareServer) Type() servertype.ServerType { return servertype.Rpc }
+func (bareServer) EndType() endtype.EndType    { return endtype.Backend }
+
+func TestAddServerNoLifecycle(t *testing.T) {
+	app := newTestApp(t)
+	if err := app.AddServer(bareServer{}); err == nil {
+		t.Fatal("no lifecycle error need, but nil")
+	}
+	if len(app.ServerTypes()) != 0 {
+		t.Errorf("rejected server not added need, but types %v", app.ServerTypes())
+	}
+	if err := app.Run(); err != nil {
+		t.Errorf("run without the rejected server need, but %v", err)
+	}
+	_ = app.Release()
+}
+
+// bootServer run the callback when started
+type bootServer struct {
+	*testServer
+	onStart func()
+}
+
+func (s *bootServer) Start(ctx context.Context) error {
+	s.onStart()
+	return s.testServer.Start(ctx)
+}
+
+func TestAddServerRunning(t *testing.T) {
+	tests := []struct {
+		name      string
+		do        func(t *testing.T, app *application.Application, rec *recorder)
+		wantStart []string
+		wantStop  []string
+		wantErr   bool
+	}{
+		{
+			name: "added while running",
+			do: func(t *testing.T, app *application.Application, rec *recorder) {
+				mustAdd(t, app, newTestServer("live", rec))
+			},
+			wantStart: []string{"live"},
+		},
+		{
+			name: "duplicate id",
+			do: func(t *testing.T, app *application.Application, rec *recorder) {
+				if err := app.AddServer(newTestServer("base", rec)); err == nil {
+					t.Error("duplicate id error need, but nil")
+				}
+			},
+			wantStart: []string{"base"},
+		},
+		{
+			name: "deleted while running",
+			do: func(t *testing.T, app *application.Application, rec *recorder) {
+				s, _ := app.GetTypeServer(servertype.Rpc, endtype.Backend, "base")
+				if err := app.DelServer(s); err != nil {
+					t.Error(err)
+				}
+			},
+			wantStop: []string{"base"},
+		},
+		{
+			name: "added after release",
+			do: func(t *testing.T, app *application.Application, rec *recorder) {
+				_ = app.Release()
+				rec.add("released")
+				mustAdd(t, app, newTestServer("late", rec))
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := &recorder{}
+			app := newTestApp(t)
+			defer app.Release()
+			mustAdd(t, app, newTestServer("base", rec))
+			if err := app.Run(); err != nil {
+				t.Fatal(err)
+			}
+			tt.do(t, app, rec)
+			for _, id := range tt.wantStart {
+				if rec.index("start:"+id) == -1 {
+					t.Errorf("%s started need, but %v", id, rec.list())
+				}
+			}
+			for _, id := range tt.wantStop {
+				if rec.index("stop:"+id) == -1 {
+					t.Errorf("%s stopped need, but %v", id, rec.list())
+				}
+			}
+			if tt.wantStop == nil && rec.index("released") == -1 && rec.index("stop:base") != -1 {
+				t.Errorf("base not stopped need, but %v", rec.list())
+			}
+			if rec.index("start:late") != -1 {
+				t.Errorf("late not started need, but %v", rec.list())
+			}
+		})
+	}
+}
+
+func TestAddServerWhileBooting(t *testing.T) {
+	rec := &recorder{}
+	app := newTestApp(t)
+	defer app.Release()
+	late := newTestServer("late", rec)
+	booting := &bootServer{testServer: newTestServer("boot", rec), onStart: func() {
+		mustAdd(t, app, late)
+	}}
+	mustAdd(t, app, booting)
+	if err := app.Run(); err != nil {
+		t.Fatal(err)
+	}
+	if rec.index("start:late") <= rec.index("start:boot") {
+		t.Errorf("late started after boot need, but %v", rec.list())
+	}
+}
@@ -0,0 +1,205 @@
+package discovery
+
+import (
+	"context"
+	"github.com/obnahsgnaw/application/pkg/utils"
+	"github.com/obnahsgnaw/application/service/regCenter"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Endpoint a discovered service endpoint
+type Endpoint struct {
+	Key      string // the register center key
+	Addr     string
+	Weight   int
+	Instance *regCenter.ServiceInstance
+}
+
+// Filter filter the discovered endpoints
+type Filter func(e Endpoint) bool
+
+// WithTag filter the endpoints with the tag
+func WithTag(tag string) Filter {
+	return func(e Endpoint) bool {
+		return e.Instance.HasTag(tag)
+	}
+}
+
+// WithVersion filter the endpoints of the version
+func WithVersion(version string) Filter {
+	return func(e Endpoint) bool {
+		return e.Instance.Version == version
+	}
+}
+
+// WithZone filter the endpoints in the zone
+func WithZone(zone string) Filter {
+	return func(e Endpoint) bool {
+		return e.Instance.Zone == zone
+	}
+}
+
+func discoveryError(msg string, err error) error {
+	return utils.TitledError("discovery error", msg, err)
+}
+
+// Discovery a live endpoint list of the register center prefix, e.g. dev/rpc/backend/api/auth
+type Discovery struct {
+	ctx       context.Context
+	cancel    context.CancelFunc
+	prefix    string
+	filters   []Filter
+	mu        sync.RWMutex
+	endpoints map[string]Endpoint
+	values    map[string]string
+	list      []Endpoint
+	deliverMu sync.Mutex
+	subMu     sync.Mutex
+	subs      map[int]func([]Endpoint)
+	subId     int
+}
+
+// New return a discovery watching the prefix until ctx done or closed, only the endpoints match all the filters are kept
+func New(ctx context.Context, r regCenter.Register, prefix string, filters ...Filter) (*Discovery, error) {
+	if r == nil {
+		return nil, discoveryError("register is required", nil)
+	}
+	prefix = strings.TrimSuffix(prefix, "/")
+	if prefix == "" {
+		return nil, discoveryError("prefix is required", nil)
+	}
+	d := &Discovery{
+		prefix:    prefix,
+		filters:   filters,
+		endpoints: make(map[string]Endpoint),
+		values:    make(map[string]string),
+		subs:      make(map[int]func([]Endpoint)),
+	}
+	d.ctx, d.cancel = context.WithCancel(ctx)
+	if err := r.Watch(d.ctx, prefix+"/", d.handle); err != nil {
+		d.cancel()
+		return nil, discoveryError("watch failed", err)
+	}
+	return d, nil
+}
+
+// Prefix return the watched prefix
+func (d *Discovery) Prefix() string {
+	return d.prefix
+}
+
+// handle a watched change, the changes are applied and notified in order under the delivery lock
+func (d *Discovery) handle(key, val string, isDel bool) {
+	if d.ctx.Err() != nil {
+		return
+	}
+	// the instance entry only, not the nested keys of the legacy multi-key values
+	if strings.Contains(strings.TrimPrefix(key, d.prefix+"/"), "/") {
+		return
+	}
+	d.deliverMu.Lock()
+	defer d.deliverMu.Unlock()
+	var e Endpoint
+	keep := false
+	if !isDel && val != "" {
+		e, keep = d.endpoint(key, val)
+	}
+	d.mu.Lock()
+	if !keep {
+		if _, ok := d.endpoints[key]; !ok {
+			d.mu.Unlock()
+			return
+		}
+		delete(d.endpoints, key)
+		delete(d.values, key)
+	} else {
+		if old, ok := d.values[key]; ok && old == val {
+			d.mu.Unlock()
+			return
+		}
+		d.endpoints[key] = e
+		d.values[key] = val
+	}
+	d.list = make([]Endpoint, 0, len(d.endpoints))
+	for _, e := range d.endpoints {
+		d.list = append(d.list, e)
+	}
+	sort.Slice(d.list, func(i, j int) bool {
+		return d.list[i].Key < d.list[j].Key
+	})
+	list := d.list
+	d.mu.Unlock()
+	d.notify(list)
+}
+
+// endpoint decode the registered value, return false if invalid or filtered
+func (d *Discovery) endpoint(key, val string) (Endpoint, bool) {
+	instance, err := regCenter.ParseInstance(val)
+	if err != nil || instance.Address == "" {
+		return Endpoint{}, false
+	}
+	weight := instance.Weight
+	if weight <= 0 {
+		weight = 1
+	}
+	e := Endpoint{Key: key, Addr: instance.Address, Weight: weight, Instance: instance}
+	for _, f := range d.filters {
+		if !f(e) {
+			return e, false
+		}
+	}
+	return e, true
+}
+
+func (d *Discovery) notify(list []Endpoint) {
+	d.subMu.Lock()
+	subs := make([]func([]Endpoint), 0, len(d.subs))
+	for _, sub := range d.subs {
+		subs = append(subs, sub)
+	}
+	d.subMu.Unlock()
+	for _, sub := range subs {
+		sub(append([]Endpoint(nil), list...))
+	}
+}
+
+// Endpoints return the current endpoints sorted by key
+func (d *Discovery) Endpoints() []Endpoint {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	return append([]Endpoint(nil), d.list...)
+}
+
+// Subscribe call the handler with the current endpoints and on each change in order, return the unsubscribe func,
+// the handler should not subscribe
+func (d *Discovery) Subscribe(handler func([]Endpoint)) (unsubscribe func()) {
+	d.deliverMu.Lock()
+	defer d.deliverMu.Unlock()
+	d.subMu.Lock()
+	d.subId++
+	id := d.subId
+	d.subs[id] = handler
+	d.subMu.Unlock()
+	handler(d.Endpoints())
+	return func() {
+		d.subMu.Lock()
+		delete(d.subs, id)
+		d.subMu.Unlock()
+	}
+}
+
+// Use subscribe the picker to the endpoint changes, return the picker
+func (d *Discovery) Use(p Picker) Picker {
+	d.Subscribe(p.Update)
+	return p
+}
+
+// Close stop watching
+func (d *Discovery) Close() {
+	d.cancel()
+	d.subMu.Lock()
+	d.subs = make(map[int]func([]Endpoint))
+	d.subMu.Unlock()
+}
@@ -0,0 +1,158 @@
+package discovery
+
+import (
+	"context"
+	"github.com/obnahsgnaw/application/service/regCenter"
+	"strconv"
+	"sync"
+	"testing"
+)
+
+func newTestDiscovery(t *testing.T) (*regCenter.LocalRegister, *Discovery) {
+	r, _ := regCenter.NewLocalRegister(context.Background())
+	t.Cleanup(r.Release)
+	_ = r.Register(context.Background(), "dev/rpc/backend/api/auth/127.0.0.1:80", "127.0.0.1:80", 0)
+	_ = r.Register(context.Background(), "dev/rpc/backend/api/auth2/127.0.0.1:90", "127.0.0.1:90", 0)
+	d, err := New(context.Background(), r, "dev/rpc/backend/api/auth")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return r, d
+}
+
+func TestDiscoveryEndpoints(t *testing.T) {
+	r, d := newTestDiscovery(t)
+	defer d.Close()
+	var notified [][]Endpoint
+	unsubscribe := d.Subscribe(func(endpoints []Endpoint) {
+		notified = append(notified, endpoints)
+	})
+	if list := d.Endpoints(); len(list) != 1 || list[0].Addr != "127.0.0.1:80" {
+		t.Fatalf("endpoint 127.0.0.1:80 need, but %v", list)
+	}
+	_ = r.Register(context.Background(), "dev/rpc/backend/api/auth/127.0.0.1:81", "127.0.0.1:81", 0)
+	_ = r.Unregister(context.Background(), "dev/rpc/backend/api/auth/127.0.0.1:80")
+	if list := d.Endpoints(); len(list) != 1 || list[0].Addr != "127.0.0.1:81" {
+		t.Fatalf("endpoint 127.0.0.1:81 need, but %v", list)
+	}
+	unsubscribe()
+	_ = r.Register(context.Background(), "dev/rpc/backend/api/auth/127.0.0.1:82", "127.0.0.1:82", 0)
+	if len(notified) != 3 {
+		t.Errorf("3 notifications need, but %d", len(notified))
+	}
+}
+
+func TestDiscoveryNestedKeys(t *testing.T) {
+	r, d := newTestDiscovery(t)
+	defer d.Close()
+	_ = r.Register(context.Background(), "dev/rpc/backend/api/auth/127.0.0.1:80/extra", "127.0.0.1:99", 0)
+	if list := d.Endpoints(); len(list) != 1 || list[0].Addr != "127.0.0.1:80" {
+		t.Errorf("nested key skipped need, but %v", list)
+	}
+}
+
+func TestDiscoveryOrderedDelivery(t *testing.T) {
+	r, d := newTestDiscovery(t)
+	defer d.Close()
+	var mu sync.Mutex
+	var last []Endpoint
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(2)
+		go func(i int) {
+			defer wg.Done()
+			addr := "127.0.0.2:" + strconv.Itoa(1000+i)
+			_ = r.Register(context.Background(), "dev/rpc/backend/api/auth/"+addr, addr, 0)
+		}(i)
+		go func() {
+			defer wg.Done()
+			d.Subscribe(func(endpoints []Endpoint) {
+				mu.Lock()
+				last = endpoints
+				mu.Unlock()
+			})
+		}()
+	}
+	wg.Wait()
+	mu.Lock()
+	defer mu.Unlock()
+	if len(last) != 21 || len(d.Endpoints()) != 21 {
+		t.Errorf("the final 21 endpoints notified last need, but %d", len(last))
+	}
+}
+
+func TestPickers(t *testing.T) {
+	endpoints := []Endpoint{
+		{Key: "a", Addr: "a", Weight: 3},
+		{Key: "b", Addr: "b", Weight: 1},
+	}
+	rr := NewRoundRobinPicker()
+	rr.Update(endpoints)
+	first, _ := rr.Pick("")
+	second, _ := rr.Pick("")
+	if first.Addr == second.Addr {
+		t.Errorf("round robin picks different endpoints need, but %s twice", first.Addr)
+	}
+
+	w := NewWeightedPicker()
+	w.Update(endpoints)
+	counts := make(map[string]int)
+	for i := 0; i < 8; i++ {
+		e, _ := w.Pick("")
+		counts[e.Addr]++
+	}
+	if counts["a"] != 6 || counts["b"] != 2 {
+		t.Errorf("weighted 6:2 need, but %v", counts)
+	}
+
+	ch := NewConsistentHashPicker(0)
+	ch.Update(endpoints)
+	e1, _ := ch.Pick("user-1")
+	ch.Update(append(endpoints, Endpoint{Key: "c", Addr: "c"}))
+	e2, _ := ch.Pick("user-1")
+	if e2.Addr != e1.Addr && e2.Addr != "c" {
+		t.Errorf("same endpoint or the new one need, but %s then %s", e1.Addr, e2.Addr)
+	}
+
+	r := NewRandomPicker()
+	if _, err := r.Pick(""); err != ErrNoEndpoint {
+		t.Errorf("no endpoint error need, but %v", err)
+	}
+}
+
+func TestDiscoveryInstances(t *testing.T) {
+	r, _ := regCenter.NewLocalRegister(context.Background())
+	defer r.Release()
+	v1 := &regCenter.RegInfo{
+		AppId:      "dev",
+		ServerInfo: regCenter.ServerInfo{Id: "auth", Type: "api", EndType: "backend"},
+		Host:       "127.0.0.1:80",
+		Instance:   &regCenter.ServiceInstance{Version: "v1", Weight: 5, Zone: "a", Tags: []string{"canary"}},
+	}
+	v2 := &regCenter.RegInfo{
+		AppId:      "dev",
+		ServerInfo: regCenter.ServerInfo{Id: "auth", Type: "api", EndType: "backend"},
+		Instance:   &regCenter.ServiceInstance{Address: "127.0.0.1:81", Version: "v2", Zone: "b"},
+	}
+	for _, info := range []*regCenter.RegInfo{v1, v2} {
+		for k, v := range info.Kvs() {
+			_ = r.Register(context.Background(), k, v, 0)
+		}
+	}
+	_ = r.Register(context.Background(), v1.Prefix()+"/auth/127.0.0.1:82", "127.0.0.1:82", 0)
+
+	d, _ := New(context.Background(), r, v1.Prefix()+"/auth")
+	list := d.Endpoints()
+	if len(list) != 3 {
+		t.Fatalf("3 endpoints need, but %v", list)
+	}
+	if list[0].Weight != 5 || list[0].Instance.Id != "auth" || list[1].Addr != "127.0.0.1:81" || list[2].Addr != "127.0.0.1:82" {
+		t.Errorf("decoded instances need, but %v %v %v", list[0], list[1], list[2])
+	}
+	for name, f := range map[string]Filter{"tag": WithTag("canary"), "version": WithVersion("v1"), "zone": WithZone("a")} {
+		fd, _ := New(context.Background(), r, v1.Prefix()+"/auth", f)
+		if list = fd.Endpoints(); len(list) != 1 || list[0].Addr != "127.0.0.1:80" {
+			t.Errorf("%s filtered endpoint 127.0.0.1:80 need, but %v", name, list)
+		}
+	}
+}
@@ -0,0 +1,172 @@
+package discovery
+
+import (
+	"errors"
+	"hash/crc32"
+	"math/rand"
+	"sort"
+	"strconv"
+	"sync"
+)
+
+// ErrNoEndpoint no endpoint to pick
+var ErrNoEndpoint = errors.New("discovery error: no endpoint available")
+
+// Picker pick an endpoint from the updated endpoints, the key is used by the consistent hash picker
+type Picker interface {
+	Update(endpoints []Endpoint)
+	Pick(key string) (Endpoint, error)
+}
+
+type basePicker struct {
+	mu        sync.RWMutex
+	endpoints []Endpoint
+}
+
+func (p *basePicker) Update(endpoints []Endpoint) {
+	p.mu.Lock()
+	p.endpoints = endpoints
+	p.mu.Unlock()
+}
+
+// RoundRobinPicker pick the endpoints in turn
+type RoundRobinPicker struct {
+	basePicker
+	next int
+}
+
+func NewRoundRobinPicker() *RoundRobinPicker {
+	return &RoundRobinPicker{}
+}
+
+func (p *RoundRobinPicker) Pick(string) (Endpoint, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if len(p.endpoints) == 0 {
+		return Endpoint{}, ErrNoEndpoint
+	}
+	e := p.endpoints[p.next%len(p.endpoints)]
+	p.next = (p.next + 1) % len(p.endpoints)
+	return e, nil
+}
+
+// RandomPicker pick a random endpoint
+type RandomPicker struct {
+	basePicker
+}
+
+func NewRandomPicker() *RandomPicker {
+	return &RandomPicker{}
+}
+
+func (p *RandomPicker) Pick(string) (Endpoint, error) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	if len(p.endpoints) == 0 {
+		return Endpoint{}, ErrNoEndpoint
+	}
+	return p.endpoints[rand.Intn(len(p.endpoints))], nil
+}
+
+// WeightedPicker the smooth weighted round-robin picker, a weight <= 0 counts as 1
+type WeightedPicker struct {
+	mu      sync.Mutex
+	weights []*weighted
+}
+
+type weighted struct {
+	endpoint Endpoint
+	weight   int
+	current  int
+}
+
+func NewWeightedPicker() *WeightedPicker {
+	return &WeightedPicker{}
+}
+
+func (p *WeightedPicker) Update(endpoints []Endpoint) {
+	weights := make([]*weighted, len(endpoints))
+	for i, e := range endpoints {
+		w := e.Weight
+		if w <= 0 {
+			w = 1
+		}
+		weights[i] = &weighted{endpoint: e, weight: w}
+	}
+	p.mu.Lock()
+	p.weights = weights
+	p.mu.Unlock()
+}
+
+func (p *WeightedPicker) Pick(string) (Endpoint, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if len(p.weights) == 0 {
+		return Endpoint{}, ErrNoEndpoint
+	}
+	total := 0
+	var best *weighted
+	for _, w := range p.weights {
+		w.current += w.weight
+		total += w.weight
+		if best == nil || w.current > best.current {
+			best = w
+		}
+	}
+	best.current -= total
+	return best.endpoint, nil
+}
+
+// ConsistentHashPicker pick the endpoint of the key on a hash ring, the same key picks the same endpoint while it exists
+type ConsistentHashPicker struct {
+	mu       sync.RWMutex
+	replicas int
+	hashes   []uint32
+	ring     map[uint32]Endpoint
+}
+
+// NewConsistentHashPicker return a consistent hash picker with the virtual nodes per endpoint, default 100
+func NewConsistentHashPicker(replicas int) *ConsistentHashPicker {
+	if replicas <= 0 {
+		replicas = 100
+	}
+	return &ConsistentHashPicker{replicas: replicas, ring: make(map[uint32]Endpoint)}
+}
+
+func (p *ConsistentHashPicker) Update(endpoints []Endpoint) {
+	ring := make(map[uint32]Endpoint, len(endpoints)*p.replicas)
+	hashes := make([]uint32, 0, len(endpoints)*p.replicas)
+	for _, e := range endpoints {
+		for i := 0; i < p.replicas; i++ {
+			h := crc32.ChecksumIEEE([]byte(strconv.Itoa(i) + "#" + e.Key))
+			if _, ok := ring[h]; ok {
+				continue
+			}
+			ring[h] = e
+			hashes = append(hashes, h)
+		}
+	}
+	sort.Slice(hashes, func(i, j int) bool {
+		return hashes[i] < hashes[j]
+	})
+	p.mu.Lock()
+	p.ring = ring
+	p.hashes = hashes
+	p.mu.Unlock()
+}
+
+func (p *ConsistentHashPicker) Pick(key string) (Endpoint, error) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	if len(p.hashes) == 0 {
+		return Endpoint{}, ErrNoEndpoint
+	}
+	h := crc32.ChecksumIEEE([]byte(key))
+	i := sort.Search(len(p.hashes), func(i int) bool {
+		return p.hashes[i] >= h
+	})
+	if i == len(p.hashes) {
+		i = 0
+	}
+	return p.ring[p.hashes[i]], nil
+}
@@ -0,0 +1,69 @@
+package resolver
+
+import (
+	"context"
+	"github.com/obnahsgnaw/application/service/discovery"
+	"github.com/obnahsgnaw/application/service/regCenter"
+	"google.golang.org/grpc/resolver"
+	"strings"
+)
+
+// Scheme the grpc target scheme, e.g. appreg:///dev/rpc/backend/api/auth
+const Scheme = "appreg"
+
+// Builder the grpc resolver builder resolves the target endpoint as the register center prefix
+type Builder struct {
+	register regCenter.Register
+	filters  []discovery.Filter
+}
+
+// NewBuilder return a resolver builder on the register center, only the endpoints match the filters are resolved
+func NewBuilder(r regCenter.Register, filters ...discovery.Filter) *Builder {
+	return &Builder{register: r, filters: filters}
+}
+
+// Register register the resolver builder of the register center globally, call it at init time
+func Register(r regCenter.Register, filters ...discovery.Filter) {
+	resolver.Register(NewBuilder(r, filters...))
+}
+
+func (b *Builder) Scheme() string {
+	return Scheme
+}
+
+func (b *Builder) Build(target resolver.Target, cc resolver.ClientConn, _ resolver.BuildOptions) (resolver.Resolver, error) {
+	ctx, cancel := context.WithCancel(context.Background())
+	d, err := discovery.New(ctx, b.register, strings.TrimPrefix(target.Endpoint, "/"), b.filters...)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	r := &registerResolver{cancel: cancel, discovery: d}
+	d.Subscribe(func(endpoints []discovery.Endpoint) {
+		// the empty state drops the removed addresses, the error makes the picker fail fast
+		if len(endpoints) == 0 {
+			_ = cc.UpdateState(resolver.State{Addresses: nil})
+			cc.ReportError(discovery.ErrNoEndpoint)
+			return
+		}
+		addresses := make([]resolver.Address, len(endpoints))
+		for i, e := range endpoints {
+			addresses[i] = resolver.Address{Addr: e.Addr}
+		}
+		_ = cc.UpdateState(resolver.State{Addresses: addresses})
+	})
+	return r, nil
+}
+
+type registerResolver struct {
+	cancel    context.CancelFunc
+	discovery *discovery.Discovery
+}
+
+// ResolveNow the addresses are pushed by the register center watch
+func (r *registerResolver) ResolveNow(resolver.ResolveNowOptions) {}
+
+func (r *registerResolver) Close() {
+	r.discovery.Close()
+	r.cancel()
+}
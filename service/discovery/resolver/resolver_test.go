@@ -0,0 +1,68 @@
+package resolver
+
+import (
+	"context"
+	"github.com/obnahsgnaw/application/service/regCenter"
+	"google.golang.org/grpc/resolver"
+	"google.golang.org/grpc/serviceconfig"
+	"sync"
+	"testing"
+)
+
+type testClientConn struct {
+	mu     sync.Mutex
+	states []resolver.State
+	errs   []error
+}
+
+func (c *testClientConn) UpdateState(s resolver.State) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.states = append(c.states, s)
+	return nil
+}
+
+func (c *testClientConn) ReportError(err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.errs = append(c.errs, err)
+}
+
+func (c *testClientConn) NewAddress([]resolver.Address) {}
+
+func (c *testClientConn) NewServiceConfig(string) {}
+
+func (c *testClientConn) ParseServiceConfig(string) *serviceconfig.ParseResult {
+	return nil
+}
+
+func TestResolver(t *testing.T) {
+	reg, _ := regCenter.NewLocalRegister(context.Background())
+	defer reg.Release()
+	_ = reg.Register(context.Background(), "dev/rpc/backend/api/auth/127.0.0.1:80", "127.0.0.1:80", 0)
+	cc := &testClientConn{}
+	b := NewBuilder(reg)
+	r, err := b.Build(resolver.Target{Scheme: Scheme, Endpoint: "dev/rpc/backend/api/auth"}, cc, resolver.BuildOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	_ = reg.Register(context.Background(), "dev/rpc/backend/api/auth/127.0.0.1:81", "127.0.0.1:81", 0)
+	_ = reg.Unregister(context.Background(), "dev/rpc/backend/api/auth/127.0.0.1:80")
+	_ = reg.Unregister(context.Background(), "dev/rpc/backend/api/auth/127.0.0.1:81")
+
+	cc.mu.Lock()
+	defer cc.mu.Unlock()
+	if len(cc.states) != 4 {
+		t.Fatalf("4 states need, but %d", len(cc.states))
+	}
+	if addrs := cc.states[2].Addresses; len(addrs) != 1 || addrs[0].Addr != "127.0.0.1:81" {
+		t.Errorf("address 127.0.0.1:81 need, but %v", addrs)
+	}
+	if last := cc.states[3].Addresses; len(last) != 0 {
+		t.Errorf("empty final state need, but %v", last)
+	}
+	if len(cc.errs) != 1 {
+		t.Errorf("no endpoint error need, but %v", cc.errs)
+	}
+}
@@ -2,12 +2,14 @@ package event
 
 import (
 	"github.com/asaskevich/EventBus"
+	"time"
 )
 
 // Manger event manager
 type Manger struct {
 	bus           EventBus.Bus
 	eventHandlers []*Handler
+	observer      func(topic string, latency time.Duration)
 }
 
 // Handler event handler
@@ -47,10 +49,19 @@ func (m *Manger) Register(topic string, handle func(e *Event)) {
 	})
 }
 
+// Observe set the observer called after each fire with the handling latency
+func (m *Manger) Observe(observer func(topic string, latency time.Duration)) {
+	m.observer = observer
+}
+
 // Fire event
 func (m *Manger) Fire(e *Event) {
+	start := time.Now()
 	m.bus.Publish(e.Topic, e)
 	m.bus.WaitAsync()
+	if m.observer != nil {
+		m.observer(e.Topic, time.Since(start))
+	}
 }
 
 // NewEvent return a new event
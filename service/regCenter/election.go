@@ -0,0 +1,23 @@
+package regCenter
+
+import "context"
+
+// Election a leader election
+type Election interface {
+	// Campaign block until elected or ctx done
+	Campaign(ctx context.Context, name, val string) (Leadership, error)
+	// Leader return the value of the current leader, empty if no leader
+	Leader(ctx context.Context, name string) (string, error)
+}
+
+// Leadership a gained leadership
+type Leadership interface {
+	// Lost closed when the leadership is lost or resigned
+	Lost() <-chan struct{}
+	Resign(ctx context.Context) error
+}
+
+// Elector a register center provides the leader election
+type Elector interface {
+	Election() Election
+}
@@ -5,11 +5,16 @@ import (
 	"errors"
 	"github.com/obnahsgnaw/application/pkg/etcd"
 	"github.com/obnahsgnaw/application/pkg/etcd/registercenter"
+	"go.etcd.io/etcd/api/v3/mvccpb"
+	"sync"
 	"time"
 )
 
 type EtcdRegister struct {
-	register *registercenter.EtcdRegister
+	register     *registercenter.EtcdRegister
+	election     *EtcdElection
+	electionOnce sync.Once
+	endpoints    []string
 }
 
 func NewEtcdRegister(endpoints []string, opTimeout time.Duration) (*EtcdRegister, error) {
@@ -20,7 +25,8 @@ func NewEtcdRegister(endpoints []string, opTimeout time.Duration) (*EtcdRegister
 		return nil, errors.New("etcd endpoints is required")
 	}
 	r := &EtcdRegister{
-		register: registercenter.New("", "", endpoints, opTimeout),
+		register:  registercenter.New("", "", endpoints, opTimeout),
+		endpoints: append([]string(nil), endpoints...),
 	}
 	if err := r.register.Init(); err != nil {
 		return nil, err
@@ -29,6 +35,11 @@ func NewEtcdRegister(endpoints []string, opTimeout time.Duration) (*EtcdRegister
 	return r, nil
 }
 
+// Endpoints return the etcd endpoints
+func (e *EtcdRegister) Endpoints() []string {
+	return append([]string(nil), e.endpoints...)
+}
+
 func (e *EtcdRegister) Release() {
 	if e.register != nil {
 		e.register.Release()
@@ -60,6 +71,23 @@ func (e *EtcdRegister) LastPrefixedIndex(ctx context.Context, keyPrefix string,
 	return etcd.GetLastIndex(ctx, e.Etcd().Conn(), keyPrefix, 5*time.Second, indexParser)
 }
 
+// List the prefixed entries
+func (e *EtcdRegister) List(ctx context.Context, keyPrefix string) (map[string]string, error) {
+	kvs := make(map[string]string)
+	err := etcd.GetPrefixed(ctx, e.Etcd().Conn(), keyPrefix, etcd.OpTtl, func(kv *mvccpb.KeyValue) {
+		kvs[string(kv.Key)] = string(kv.Value)
+	})
+	return kvs, err
+}
+
 func (e *EtcdRegister) Etcd() *registercenter.EtcdRegister {
 	return e.register
 }
+
+// Election return the leader election on the etcd client
+func (e *EtcdRegister) Election() Election {
+	e.electionOnce.Do(func() {
+		e.election = NewEtcdElection(e.register.Conn(), 0)
+	})
+	return e.election
+}
@@ -0,0 +1,61 @@
+package regCenter
+
+import (
+	"context"
+	clientv3 "go.etcd.io/etcd/client/v3"
+	"go.etcd.io/etcd/client/v3/concurrency"
+)
+
+// EtcdElection the leader election backed by etcd concurrency.Election
+type EtcdElection struct {
+	client *clientv3.Client
+	ttl    int
+}
+
+func NewEtcdElection(client *clientv3.Client, ttl int) *EtcdElection {
+	if ttl <= 0 {
+		ttl = 10
+	}
+	return &EtcdElection{client: client, ttl: ttl}
+}
+
+func (e *EtcdElection) Campaign(ctx context.Context, name, val string) (Leadership, error) {
+	session, err := concurrency.NewSession(e.client, concurrency.WithTTL(e.ttl), concurrency.WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	election := concurrency.NewElection(session, name)
+	if err = election.Campaign(ctx, val); err != nil {
+		_ = session.Close()
+		return nil, err
+	}
+	return &etcdLeadership{session: session, election: election}, nil
+}
+
+func (e *EtcdElection) Leader(ctx context.Context, name string) (string, error) {
+	resp, err := e.client.Get(ctx, name+"/", clientv3.WithFirstCreate()...)
+	if err != nil {
+		return "", err
+	}
+	if len(resp.Kvs) == 0 {
+		return "", nil
+	}
+	return string(resp.Kvs[0].Value), nil
+}
+
+type etcdLeadership struct {
+	session  *concurrency.Session
+	election *concurrency.Election
+}
+
+func (l *etcdLeadership) Lost() <-chan struct{} {
+	return l.session.Done()
+}
+
+func (l *etcdLeadership) Resign(ctx context.Context) error {
+	err := l.election.Resign(ctx)
+	if closeErr := l.session.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
@@ -0,0 +1,440 @@
+package regCenter
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"go.uber.org/zap"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const fileRegisterName = "register.json"
+
+type fileEntry struct {
+	Value     string `json:"value"`
+	Ttl       int64  `json:"ttl"`
+	Heartbeat int64  `json:"heartbeat"` // unix nano
+}
+
+func (e fileEntry) alive(now time.Time) bool {
+	return e.Ttl <= 0 || time.Unix(0, e.Heartbeat).Add(time.Duration(e.Ttl)*time.Second).After(now)
+}
+
+type fileOwned struct {
+	val  string
+	ttl  int64
+	keep context.Context
+	beat time.Time // the last heartbeat written, need mu locked
+}
+
+// due return the lease passes the half of the ttl before the next heartbeat
+func (o *fileOwned) due(now time.Time, interval time.Duration) bool {
+	return !now.Add(interval).Before(o.beat.Add(time.Duration(o.ttl) * time.Second / 2))
+}
+
+// FileRegister the register center persisted to a local file, shared by the processes on one host.
+// The writes are atomic under a file lock, the ttl entries are kept alive by heartbeats, the watchers poll the file.
+type FileRegister struct {
+	path      string
+	lockPath  string
+	interval  time.Duration
+	ctx       context.Context
+	cancel    context.CancelFunc
+	mu        sync.Mutex
+	owned     map[string]*fileOwned
+	watchers  map[int]*localWatcher
+	watcherId int
+	last      map[string]string
+	changed   chan struct{}
+	pollMu    sync.Mutex
+	logger    *zap.Logger
+	now       func() time.Time
+}
+
+// NewFileRegister return a file register, the path is a file or an existing directory, the interval is the poll and heartbeat interval, default 1s,
+// it must be below the ttl of the registered keys
+func NewFileRegister(path string, interval time.Duration) (*FileRegister, error) {
+	if path == "" {
+		return nil, fileRegisterError("path is required", nil)
+	}
+	if info, err := os.Stat(path); err == nil && info.IsDir() {
+		path = filepath.Join(path, fileRegisterName)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fileRegisterError("create dir failed", err)
+	}
+	if interval <= 0 {
+		interval = time.Second
+	}
+	r := &FileRegister{
+		path:     path,
+		lockPath: path + ".lock",
+		interval: interval,
+		owned:    make(map[string]*fileOwned),
+		watchers: make(map[int]*localWatcher),
+		changed:  make(chan struct{}, 1),
+		logger:   zap.NewNop(),
+		now:      time.Now,
+	}
+	r.ctx, r.cancel = context.WithCancel(context.Background())
+	if _, err := r.read(); err != nil {
+		return nil, err
+	}
+	go r.run()
+	return r, nil
+}
+
+func fileRegisterError(msg string, err error) error {
+	if err != nil {
+		msg += ": " + err.Error()
+	}
+	return errors.New("file register error: " + msg)
+}
+
+// Path return the register file path
+func (r *FileRegister) Path() string {
+	return r.path
+}
+
+// SetLogger set the logger of the heartbeat and poll failures
+func (r *FileRegister) SetLogger(l *zap.Logger) {
+	if l == nil {
+		return
+	}
+	r.mu.Lock()
+	r.logger = l
+	r.mu.Unlock()
+}
+
+func (r *FileRegister) log() *zap.Logger {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.logger
+}
+
+func (r *FileRegister) clock() time.Time {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.now()
+}
+
+// Endpoints return the register file path
+func (r *FileRegister) Endpoints() []string {
+	return []string{r.path}
+}
+
+// Release stop the heartbeat and watch, the ttl entries expire after their ttl
+func (r *FileRegister) Release() {
+	r.cancel()
+}
+
+// Register put the key, a ttl lease is kept alive by heartbeats until ctx done or released, the ttl must be above the interval,
+// the heartbeat rewrites the file only when a lease passes the half of its ttl
+func (r *FileRegister) Register(ctx context.Context, key, val string, ttl int64) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if ttl > 0 && time.Duration(ttl)*time.Second <= r.interval {
+		return fileRegisterError("ttl["+strconv.FormatInt(ttl, 10)+"s] must be above the heartbeat interval["+r.interval.String()+"]", nil)
+	}
+	now := r.clock()
+	err := r.update(func(entries map[string]fileEntry) {
+		entries[key] = fileEntry{Value: val, Ttl: ttl, Heartbeat: now.UnixNano()}
+	})
+	if err != nil {
+		return err
+	}
+	if ttl > 0 {
+		r.mu.Lock()
+		r.owned[key] = &fileOwned{val: val, ttl: ttl, keep: ctx, beat: now}
+		r.mu.Unlock()
+	}
+	r.notifyChanged()
+	return nil
+}
+
+func (r *FileRegister) Unregister(_ context.Context, key string) error {
+	r.mu.Lock()
+	delete(r.owned, key)
+	r.mu.Unlock()
+	err := r.update(func(entries map[string]fileEntry) {
+		delete(entries, key)
+	})
+	if err != nil {
+		return err
+	}
+	r.notifyChanged()
+	return nil
+}
+
+// Watch the prefixed keys until ctx done or released, the current values are reported first
+func (r *FileRegister) Watch(ctx context.Context, keyPrefix string, handler func(key string, val string, isDel bool)) error {
+	if handler == nil {
+		return nil
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	r.pollMu.Lock()
+	current, err := r.alive()
+	if err != nil {
+		r.pollMu.Unlock()
+		return err
+	}
+	watchers := r.poll(current)
+	w := &localWatcher{prefix: keyPrefix, handler: handler}
+	r.mu.Lock()
+	r.watcherId++
+	id := r.watcherId
+	r.watchers[id] = w
+	r.mu.Unlock()
+	// the current values are queued before the later polled changes
+	for k, v := range current {
+		if strings.HasPrefix(k, keyPrefix) {
+			w.enqueue(localEvent{key: k, val: v})
+		}
+	}
+	r.pollMu.Unlock()
+	deliver(watchers)
+	w.deliver()
+	go func() {
+		select {
+		case <-ctx.Done():
+		case <-r.ctx.Done():
+		}
+		r.mu.Lock()
+		delete(r.watchers, id)
+		r.mu.Unlock()
+	}()
+	return nil
+}
+
+// List the prefixed alive entries
+func (r *FileRegister) List(_ context.Context, keyPrefix string) (map[string]string, error) {
+	entries, err := r.alive()
+	if err != nil {
+		return nil, err
+	}
+	for k := range entries {
+		if !strings.HasPrefix(k, keyPrefix) {
+			delete(entries, k)
+		}
+	}
+	return entries, nil
+}
+
+func (r *FileRegister) LastPrefixedIndex(_ context.Context, keyPrefix string, indexParser func(key string) int) (int, error) {
+	entries, err := r.alive()
+	if err != nil {
+		return -1, err
+	}
+	index := -1
+	for k := range entries {
+		if strings.HasPrefix(k, keyPrefix) {
+			if i := indexParser(k); i > index {
+				index = i
+			}
+		}
+	}
+	return index, nil
+}
+
+func (r *FileRegister) notifyChanged() {
+	select {
+	case r.changed <- struct{}{}:
+	default:
+	}
+}
+
+// run heartbeat the owned entries and poll the file until released
+func (r *FileRegister) run() {
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-r.ctx.Done():
+			return
+		case <-ticker.C:
+			r.heartbeat()
+		case <-r.changed:
+		}
+		var watchers []*localWatcher
+		r.pollMu.Lock()
+		if current, err := r.alive(); err == nil {
+			watchers = r.poll(current)
+		} else {
+			r.log().Warn("file register poll failed", zap.Error(err))
+		}
+		r.pollMu.Unlock()
+		deliver(watchers)
+	}
+}
+
+// heartbeat refresh the owned leases passing the half of their ttl, the file is not written if none
+func (r *FileRegister) heartbeat() {
+	now := r.clock()
+	r.mu.Lock()
+	owned := make(map[string]*fileOwned)
+	for k, o := range r.owned {
+		if o.keep.Err() != nil {
+			delete(r.owned, k)
+			continue
+		}
+		if o.due(now, r.interval) {
+			owned[k] = o
+		}
+	}
+	r.mu.Unlock()
+	if len(owned) == 0 {
+		return
+	}
+	var refreshed []*fileOwned
+	err := r.update(func(entries map[string]fileEntry) {
+		refreshed = refreshed[:0]
+		for k, o := range owned {
+			// unregistered or deleted since the snapshot, or replaced by another owner
+			if e, ok := entries[k]; !ok || e.Value != o.val || !r.owns(k, o) {
+				continue
+			}
+			entries[k] = fileEntry{Value: o.val, Ttl: o.ttl, Heartbeat: now.UnixNano()}
+			refreshed = append(refreshed, o)
+		}
+	})
+	if err != nil {
+		r.log().Warn("file register heartbeat failed", zap.Error(err))
+		return
+	}
+	r.mu.Lock()
+	for _, o := range refreshed {
+		o.beat = now
+	}
+	r.mu.Unlock()
+}
+
+// owns return whether the key is still owned by the registration
+func (r *FileRegister) owns(key string, o *fileOwned) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.owned[key] == o
+}
+
+// poll queue the changes from the last snapshot to the watchers, return them to deliver after pollMu unlocked, need pollMu locked
+func (r *FileRegister) poll(current map[string]string) []*localWatcher {
+	var changes []localEvent
+	for k, v := range current {
+		if old, ok := r.last[k]; !ok || old != v {
+			changes = append(changes, localEvent{key: k, val: v})
+		}
+	}
+	for k, v := range r.last {
+		if _, ok := current[k]; !ok {
+			changes = append(changes, localEvent{key: k, val: v, isDel: true})
+		}
+	}
+	r.last = current
+	if len(changes) == 0 {
+		return nil
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var watchers []*localWatcher
+	for _, w := range r.watchers {
+		matched := false
+		for _, c := range changes {
+			if strings.HasPrefix(c.key, w.prefix) {
+				w.enqueue(c)
+				matched = true
+			}
+		}
+		if matched {
+			watchers = append(watchers, w)
+		}
+	}
+	return watchers
+}
+
+// alive return the values of the alive entries
+func (r *FileRegister) alive() (map[string]string, error) {
+	entries, err := r.read()
+	if err != nil {
+		return nil, err
+	}
+	now := r.clock()
+	values := make(map[string]string, len(entries))
+	for k, e := range entries {
+		if e.alive(now) {
+			values[k] = e.Value
+		}
+	}
+	return values, nil
+}
+
+// read the entries, the file is replaced atomically so it is read without the lock
+func (r *FileRegister) read() (map[string]fileEntry, error) {
+	entries := make(map[string]fileEntry)
+	data, err := os.ReadFile(r.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return entries, nil
+		}
+		return nil, fileRegisterError("read failed", err)
+	}
+	if len(data) == 0 {
+		return entries, nil
+	}
+	if err = json.Unmarshal(data, &entries); err != nil {
+		return nil, fileRegisterError("decode failed", err)
+	}
+	return entries, nil
+}
+
+// update modify the entries under the file lock, drop the expired entries and write to a temp file renamed to the path
+func (r *FileRegister) update(modify func(entries map[string]fileEntry)) (err error) {
+	unlock, err := lockFile(r.lockPath)
+	if err != nil {
+		return fileRegisterError("lock failed", err)
+	}
+	defer unlock()
+	entries, err := r.read()
+	if err != nil {
+		return err
+	}
+	modify(entries)
+	now := r.clock()
+	for k, e := range entries {
+		if !e.alive(now) {
+			delete(entries, k)
+		}
+	}
+	data, err := json.MarshalIndent(entries, "", "  ")
+	if err != nil {
+		return fileRegisterError("encode failed", err)
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(r.path), filepath.Base(r.path)+".tmp-*")
+	if err != nil {
+		return fileRegisterError("write failed", err)
+	}
+	defer func() {
+		if err != nil {
+			_ = os.Remove(tmp.Name())
+		}
+	}()
+	if _, err = tmp.Write(data); err == nil {
+		err = tmp.Sync()
+	}
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fileRegisterError("write failed", err)
+	}
+	if err = os.Rename(tmp.Name(), r.path); err != nil {
+		return fileRegisterError("rename failed", err)
+	}
+	return nil
+}
@@ -0,0 +1,24 @@
+//go:build !windows
+
+package regCenter
+
+import (
+	"os"
+	"syscall"
+)
+
+// lockFile acquire the exclusive lock of the lock file shared by the processes, return the unlock func
+func lockFile(path string) (func(), error) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR, 0644)
+	if err != nil {
+		return nil, err
+	}
+	if err = syscall.Flock(int(f.Fd()), syscall.LOCK_EX); err != nil {
+		_ = f.Close()
+		return nil, err
+	}
+	return func() {
+		_ = syscall.Flock(int(f.Fd()), syscall.LOCK_UN)
+		_ = f.Close()
+	}, nil
+}
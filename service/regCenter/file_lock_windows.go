@@ -0,0 +1,25 @@
+//go:build windows
+
+package regCenter
+
+import (
+	"golang.org/x/sys/windows"
+	"os"
+)
+
+// lockFile acquire the exclusive lock of the lock file shared by the processes, return the unlock func
+func lockFile(path string) (func(), error) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR, 0644)
+	if err != nil {
+		return nil, err
+	}
+	ol := new(windows.Overlapped)
+	if err = windows.LockFileEx(windows.Handle(f.Fd()), windows.LOCKFILE_EXCLUSIVE_LOCK, 0, 1, 0, ol); err != nil {
+		_ = f.Close()
+		return nil, err
+	}
+	return func() {
+		_ = windows.UnlockFileEx(windows.Handle(f.Fd()), 0, 1, 0, ol)
+		_ = f.Close()
+	}, nil
+}
@@ -0,0 +1,213 @@
+package regCenter
+
+import (
+	"context"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+	"os"
+	"testing"
+	"time"
+)
+
+// fileClock set the same clock of the registers sharing a file, advance it to expire the entries
+func fileClock(rs ...*FileRegister) func(d time.Duration) {
+	now := time.Now()
+	for _, r := range rs {
+		r.mu.Lock()
+		r.now = func() time.Time { return now }
+		r.mu.Unlock()
+	}
+	return func(d time.Duration) {
+		for _, r := range rs {
+			r.mu.Lock()
+		}
+		now = now.Add(d)
+		for _, r := range rs {
+			r.mu.Unlock()
+		}
+	}
+}
+
+func newFileRegister(t *testing.T, dir string, interval time.Duration) *FileRegister {
+	r, err := NewFileRegister(dir, interval)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(r.Release)
+	return r
+}
+
+func watchChanges(r *FileRegister) chan string {
+	changes := make(chan string, 10)
+	_ = r.Watch(context.Background(), "dev/", func(key string, val string, isDel bool) {
+		if isDel {
+			changes <- "del " + key
+		} else {
+			changes <- "put " + key + "=" + val
+		}
+	})
+	return changes
+}
+
+func waitChange(t *testing.T, changes chan string) string {
+	select {
+	case c := <-changes:
+		return c
+	case <-time.After(2 * time.Second):
+		t.Fatal("change need, but timeout")
+	}
+	return ""
+}
+
+func TestFileRegisterShared(t *testing.T) {
+	dir := t.TempDir()
+	r1 := newFileRegister(t, dir, 10*time.Millisecond)
+	// another process on the host shares the same file
+	r2 := newFileRegister(t, dir, 10*time.Millisecond)
+	changes := watchChanges(r2)
+
+	_ = r1.Register(context.Background(), "dev/rpc/auth/127.0.0.1:80", "127.0.0.1:80", 0)
+	if c := waitChange(t, changes); c != "put dev/rpc/auth/127.0.0.1:80=127.0.0.1:80" {
+		t.Errorf("put need, but %s", c)
+	}
+	_ = r1.Unregister(context.Background(), "dev/rpc/auth/127.0.0.1:80")
+	if c := waitChange(t, changes); c != "del dev/rpc/auth/127.0.0.1:80" {
+		t.Errorf("del need, but %s", c)
+	}
+}
+
+// heartbeatAt return the heartbeat written in the file of the key
+func heartbeatAt(t *testing.T, r *FileRegister, key string) int64 {
+	t.Helper()
+	entries, err := r.read()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return entries[key].Heartbeat
+}
+
+func TestFileRegisterHeartbeat(t *testing.T) {
+	r := newFileRegister(t, t.TempDir(), 500*time.Millisecond)
+	advance := fileClock(r)
+	keepCtx, stopKeep := context.WithCancel(context.Background())
+	_ = r.Register(keepCtx, "dev/kept", "1", 10)
+	_ = r.Register(context.Background(), "dev/lease", "2", 10)
+	r.mu.Lock()
+	delete(r.owned, "dev/lease") // registered by a stopped owner
+	r.mu.Unlock()
+	registered := heartbeatAt(t, r, "dev/kept")
+
+	// not written before the half of the ttl
+	advance(3 * time.Second)
+	r.heartbeat()
+	if heartbeatAt(t, r, "dev/kept") != registered {
+		t.Error("heartbeat skipped need, but written")
+	}
+	advance(2 * time.Second)
+	r.heartbeat()
+	if heartbeatAt(t, r, "dev/kept") == registered {
+		t.Error("heartbeat written need, but skipped")
+	}
+	advance(6 * time.Second)
+	kvs, _ := r.List(context.Background(), "dev/")
+	if kvs["dev/kept"] != "1" {
+		t.Errorf("kept alive entry need, but %v", kvs)
+	}
+	if _, ok := kvs["dev/lease"]; ok {
+		t.Error("not kept entry expired need, but alive")
+	}
+
+	stopKeep()
+	r.heartbeat()
+	advance(11 * time.Second)
+	if kvs, _ = r.List(context.Background(), "dev/"); len(kvs) != 0 {
+		t.Errorf("expired after keepalive stopped need, but %v", kvs)
+	}
+}
+
+func TestFileRegisterTtlInterval(t *testing.T) {
+	r := newFileRegister(t, t.TempDir(), 2*time.Second)
+	if err := r.Register(context.Background(), "dev/short", "1", 2); err == nil {
+		t.Error("ttl not above the interval rejected need, but nil")
+	}
+	if err := r.Register(context.Background(), "dev/long", "1", 3); err != nil {
+		t.Error(err)
+	}
+	if err := r.Register(context.Background(), "dev/forever", "1", 0); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestFileRegisterHeartbeatNoResurrect(t *testing.T) {
+	dir := t.TempDir()
+	r1 := newFileRegister(t, dir, 500*time.Millisecond)
+	r2 := newFileRegister(t, dir, 500*time.Millisecond)
+	advance := fileClock(r1, r2)
+	_ = r1.Register(context.Background(), "dev/deleted", "1", 10)
+	_ = r1.Register(context.Background(), "dev/replaced", "2", 10)
+	// deleted and replaced by another process, still owned by r1
+	_ = r2.Unregister(context.Background(), "dev/deleted")
+	_ = r2.Register(context.Background(), "dev/replaced", "3", 0)
+
+	advance(5 * time.Second)
+	r1.heartbeat()
+	kvs, _ := r1.List(context.Background(), "dev/")
+	if _, ok := kvs["dev/deleted"]; ok {
+		t.Error("deleted entry not resurrected need, but present")
+	}
+	if kvs["dev/replaced"] != "3" {
+		t.Errorf("replaced value kept need, but %v", kvs)
+	}
+}
+
+func TestFileRegisterExpiredWatch(t *testing.T) {
+	dir := t.TempDir()
+	owner := newFileRegister(t, dir, 500*time.Millisecond)
+	r := newFileRegister(t, dir, 500*time.Millisecond)
+	advance := fileClock(owner, r)
+	_ = owner.Register(context.Background(), "dev/rpc/auth/127.0.0.1:81", "127.0.0.1:81", 1)
+	owner.Release()
+	changes := watchChanges(r)
+	if c := waitChange(t, changes); c != "put dev/rpc/auth/127.0.0.1:81=127.0.0.1:81" {
+		t.Errorf("put need, but %s", c)
+	}
+	advance(2 * time.Second)
+	r.notifyChanged()
+	if c := waitChange(t, changes); c != "del dev/rpc/auth/127.0.0.1:81" {
+		t.Errorf("expired del need, but %s", c)
+	}
+}
+
+func TestFileRegisterReentrantHandler(t *testing.T) {
+	dir := t.TempDir()
+	r := newFileRegister(t, dir, 10*time.Millisecond)
+	writer := newFileRegister(t, dir, 10*time.Millisecond)
+	done := make(chan string, 10)
+	_ = r.Watch(context.Background(), "dev/", func(key string, val string, isDel bool) {
+		// the handler lists and watches the register again
+		_, _ = r.List(context.Background(), "dev/")
+		_ = r.Watch(context.Background(), "other/", func(string, string, bool) {})
+		done <- key
+	})
+	_ = writer.Register(context.Background(), "dev/a", "1", 0)
+	if key := waitChange(t, done); key != "dev/a" {
+		t.Errorf("dev/a need, but %s", key)
+	}
+}
+
+func TestFileRegisterHeartbeatError(t *testing.T) {
+	r := newFileRegister(t, t.TempDir(), 500*time.Millisecond)
+	advance := fileClock(r)
+	core, logs := observer.New(zap.WarnLevel)
+	r.SetLogger(zap.New(core))
+	_ = r.Register(context.Background(), "dev/kept", "1", 10)
+	// the register file is broken
+	if err := os.WriteFile(r.Path(), []byte("{"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	advance(5 * time.Second)
+	r.heartbeat()
+	if logs.FilterMessage("file register heartbeat failed").Len() == 0 {
+		t.Errorf("heartbeat failure logged need, but %v", logs.All())
+	}
+}
@@ -0,0 +1,56 @@
+package regCenter
+
+import (
+	"encoding/json"
+	"strings"
+	"time"
+)
+
+// ServiceInstance the structured service metadata registered as json in a single key
+type ServiceInstance struct {
+	Id        string     `json:"id"`
+	Address   string     `json:"address"`
+	Version   string     `json:"version,omitempty"`
+	Weight    int        `json:"weight,omitempty"`
+	Zone      string     `json:"zone,omitempty"`
+	Tags      []string   `json:"tags,omitempty"`
+	StartedAt *time.Time `json:"started_at,omitempty"`
+	Health    string     `json:"health,omitempty"`
+}
+
+const (
+	InstanceHealthy   = "healthy"
+	InstanceUnhealthy = "unhealthy"
+)
+
+// HasTag return the instance has the tag
+func (i *ServiceInstance) HasTag(tag string) bool {
+	for _, t := range i.Tags {
+		if t == tag {
+			return true
+		}
+	}
+	return false
+}
+
+// Encode return the json value
+func (i *ServiceInstance) Encode() (string, error) {
+	b, err := json.Marshal(i)
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
+
+// ParseInstance decode a registered value, the plain host values registered by the old versions are decoded as the address
+func ParseInstance(val string) (*ServiceInstance, error) {
+	val = strings.TrimSpace(val)
+	if !strings.HasPrefix(val, "{") {
+		return &ServiceInstance{Address: val}, nil
+	}
+	i := &ServiceInstance{}
+	if err := json.Unmarshal([]byte(val), i); err != nil {
+		return nil, err
+	}
+	return i, nil
+}
@@ -0,0 +1,41 @@
+package regCenter
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRegInfoKvs(t *testing.T) {
+	info := &RegInfo{
+		AppId:      "dev",
+		RegType:    "rpc",
+		ServerInfo: ServerInfo{Id: "auth", Type: "api", EndType: "backend"},
+		Instance:   &ServiceInstance{Address: "127.0.0.1:80", Version: "v1"},
+	}
+	kvs, err := info.EncodeKvs()
+	if err != nil {
+		t.Fatal(err)
+	}
+	v, ok := kvs["dev/rpc/backend/api/auth/127.0.0.1:80"]
+	if !ok {
+		t.Fatalf("key by the instance address need, but %v", kvs)
+	}
+	instance, err := ParseInstance(v)
+	if err != nil || instance.Id != "auth" || instance.Address != "127.0.0.1:80" {
+		t.Errorf("instance with the server id need, but %v %v", instance, err)
+	}
+	if strings.Contains(v, "started_at") {
+		t.Errorf("unset started_at omitted need, but %s", v)
+	}
+	if info.Host != "" || info.Instance.Id != "" || info.KeyPreGen != nil {
+		t.Errorf("info not modified need, but %+v %+v", info, info.Instance)
+	}
+
+	now := time.Now()
+	info.Instance.StartedAt = &now
+	kvs = info.Kvs()
+	if v = kvs["dev/rpc/backend/api/auth/127.0.0.1:80"]; !strings.Contains(v, "started_at") {
+		t.Errorf("started_at need, but %s", v)
+	}
+}
@@ -2,84 +2,231 @@ package regCenter
 
 import (
 	"context"
+	"errors"
 	"strings"
+	"sync"
 	"time"
 )
 
+const localSweepInterval = 500 * time.Millisecond
+
 type regVal struct {
 	Value    string
 	ttl      int64
 	expireAt time.Time
+	keep     context.Context // the lease is kept alive until done
+}
+
+// localWatcher the events are queued under the register lock and delivered in order
+type localWatcher struct {
+	prefix   string
+	handler  func(key string, val string, isDel bool)
+	mu       sync.Mutex
+	pending  []localEvent
+	draining bool
+}
+
+// enqueue the event, need the register mu locked to keep the change order
+func (w *localWatcher) enqueue(ev localEvent) {
+	w.mu.Lock()
+	w.pending = append(w.pending, ev)
+	w.mu.Unlock()
+}
+
+// deliver the queued events, a concurrent or reentrant call leaves them to the draining one
+func (w *localWatcher) deliver() {
+	w.mu.Lock()
+	if w.draining {
+		w.mu.Unlock()
+		return
+	}
+	w.draining = true
+	for len(w.pending) > 0 {
+		ev := w.pending[0]
+		w.pending = w.pending[1:]
+		w.mu.Unlock()
+		w.handler(ev.key, ev.val, ev.isDel)
+		w.mu.Lock()
+	}
+	w.draining = false
+	w.mu.Unlock()
 }
 
+type localEvent struct {
+	key   string
+	val   string
+	isDel bool
+}
+
+// LocalRegister the in-process register center, entries with a ttl expire unless kept alive
 type LocalRegister struct {
-	ctx      context.Context
-	data     map[string]regVal
-	watchers map[string][]func(key string, val string, isDel bool)
+	ctx       context.Context
+	cancel    context.CancelFunc
+	mu        sync.Mutex
+	data      map[string]regVal
+	watchers  map[int]*localWatcher
+	watcherId int
+	election  *LocalElection
+	now       func() time.Time
 }
 
 func NewLocalRegister(ctx context.Context) (*LocalRegister, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
 	r := &LocalRegister{
-		ctx:      ctx,
 		data:     make(map[string]regVal),
-		watchers: make(map[string][]func(key string, val string, isDel bool)),
+		watchers: make(map[int]*localWatcher),
+		election: NewLocalElection(),
+		now:      time.Now,
 	}
+	r.ctx, r.cancel = context.WithCancel(ctx)
+	r.watch()
 
 	return r, nil
 }
 
+// Release stop the ttl sweeper
 func (e *LocalRegister) Release() {
+	e.cancel()
 }
 
-func (e *LocalRegister) Register(_ context.Context, key, val string, ttl int64) error {
+// Election return the in-memory leader election
+func (e *LocalRegister) Election() Election {
+	return e.election
+}
+
+// Register put the key, a ttl lease is kept alive until ctx done like the etcd register,
+// so with a never done ctx such as context.Background() the key is kept until unregistered or released, use Put for a lease not kept alive
+func (e *LocalRegister) Register(ctx context.Context, key, val string, ttl int64) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	e.put(key, val, ttl, ctx)
+	return nil
+}
+
+// Put put the key with a ttl lease not kept alive, refresh it by KeepAlive
+func (e *LocalRegister) Put(key, val string, ttl int64) {
+	e.put(key, val, ttl, nil)
+}
+
+func (e *LocalRegister) put(key, val string, ttl int64, keep context.Context) {
 	v := regVal{
-		Value:    val,
-		ttl:      ttl,
-		expireAt: time.Time{},
+		Value: val,
+		ttl:   ttl,
+		keep:  keep,
 	}
+	e.mu.Lock()
 	if ttl > 0 {
-		v.expireAt = time.Now().Add(time.Duration(ttl) * time.Second)
+		v.expireAt = e.now().Add(time.Duration(ttl) * time.Second)
 	}
 	e.data[key] = v
-	e.notify(key, v.Value, false)
+	watchers := e.notify(localEvent{key: key, val: val})
+	e.mu.Unlock()
+	deliver(watchers)
+}
+
+// KeepAlive refresh the ttl lease of the key
+func (e *LocalRegister) KeepAlive(key string) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	v, ok := e.data[key]
+	if !ok {
+		return errors.New("local register error: key[" + key + "] not found or expired")
+	}
+	if v.ttl > 0 {
+		v.expireAt = e.now().Add(time.Duration(v.ttl) * time.Second)
+		e.data[key] = v
+	}
 	return nil
 }
 
-func (e *LocalRegister) notify(key, val string, del bool) {
-	for wk, wts := range e.watchers {
-		if key == wk || strings.HasPrefix(key, wk) {
-			for _, wt := range wts {
-				wt(key, val, del)
-			}
+// notify queue the event to the watchers of the key, return them to deliver after unlocked, need mu locked
+func (e *LocalRegister) notify(ev localEvent) (watchers []*localWatcher) {
+	for _, w := range e.watchers {
+		if strings.HasPrefix(ev.key, w.prefix) {
+			w.enqueue(ev)
+			watchers = append(watchers, w)
 		}
 	}
+	return
+}
+
+func deliver(watchers []*localWatcher) {
+	for _, w := range watchers {
+		w.deliver()
+	}
 }
+
 func (e *LocalRegister) Unregister(_ context.Context, key string) error {
-	if v, ok := e.data[key]; ok {
-		delete(e.data, key)
-		e.notify(key, v.Value, true)
+	e.mu.Lock()
+	v, ok := e.data[key]
+	if !ok {
+		e.mu.Unlock()
+		return nil
 	}
+	delete(e.data, key)
+	watchers := e.notify(localEvent{key: key, val: v.Value, isDel: true})
+	e.mu.Unlock()
+	deliver(watchers)
 	return nil
 }
 
-func (e *LocalRegister) Watch(_ context.Context, keyPrefix string, handler func(key string, val string, isDel bool)) error {
-	e.watchers[keyPrefix] = append(e.watchers[keyPrefix], handler)
-	e.watchNotify(keyPrefix, handler)
+// Watch the prefixed keys until ctx done, the current values are reported first
+func (e *LocalRegister) Watch(ctx context.Context, keyPrefix string, handler func(key string, val string, isDel bool)) error {
+	if handler == nil {
+		return nil
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	w := &localWatcher{prefix: keyPrefix, handler: handler}
+	e.mu.Lock()
+	e.watcherId++
+	id := e.watcherId
+	e.watchers[id] = w
+	// the current values are queued before any later change
+	for k, v := range e.data {
+		if strings.HasPrefix(k, keyPrefix) {
+			w.enqueue(localEvent{key: k, val: v.Value})
+		}
+	}
+	e.mu.Unlock()
+	if ctx.Done() != nil {
+		go func() {
+			select {
+			case <-ctx.Done():
+			case <-e.ctx.Done():
+			}
+			e.mu.Lock()
+			delete(e.watchers, id)
+			e.mu.Unlock()
+		}()
+	}
+	w.deliver()
 	return nil
 }
 
-func (e *LocalRegister) watchNotify(keyPrefix string, handler func(key string, val string, isDel bool)) {
+// List the prefixed entries
+func (e *LocalRegister) List(_ context.Context, keyPrefix string) (map[string]string, error) {
+	kvs := make(map[string]string)
+	e.mu.Lock()
+	defer e.mu.Unlock()
 	for k, v := range e.data {
-		if keyPrefix == k || strings.HasPrefix(k, keyPrefix) {
-			handler(k, v.Value, false)
+		if strings.HasPrefix(k, keyPrefix) {
+			kvs[k] = v.Value
 		}
 	}
+	return kvs, nil
 }
 
 func (e *LocalRegister) LastPrefixedIndex(_ context.Context, keyPrefix string, indexParser func(key string) int) (int, error) {
 	index := -1
+	e.mu.Lock()
+	defer e.mu.Unlock()
 	for k := range e.data {
-		if k == keyPrefix || strings.HasPrefix(k, keyPrefix) {
+		if strings.HasPrefix(k, keyPrefix) {
 			i := indexParser(k)
 			if i > index {
 				index = i
@@ -89,20 +236,40 @@ func (e *LocalRegister) LastPrefixedIndex(_ context.Context, keyPrefix string, i
 	return index, nil
 }
 
+// watch sweep the expired entries until released
 func (e *LocalRegister) watch() {
 	go func() {
+		ticker := time.NewTicker(localSweepInterval)
+		defer ticker.Stop()
 		for {
-			time.Sleep(time.Second * 3)
 			select {
 			case <-e.ctx.Done():
 				return
-			default:
-				for k, v := range e.data {
-					if v.ttl > 0 && v.expireAt.Before(time.Now()) {
-						_ = e.Unregister(e.ctx, k)
-					}
-				}
+			case <-ticker.C:
+				e.sweep()
 			}
 		}
 	}()
 }
+
+func (e *LocalRegister) sweep() {
+	var watchers []*localWatcher
+	e.mu.Lock()
+	now := e.now()
+	for k, v := range e.data {
+		if v.ttl <= 0 {
+			continue
+		}
+		if v.keep != nil && v.keep.Err() == nil {
+			v.expireAt = now.Add(time.Duration(v.ttl) * time.Second)
+			e.data[k] = v
+			continue
+		}
+		if v.expireAt.Before(now) {
+			delete(e.data, k)
+			watchers = append(watchers, e.notify(localEvent{key: k, val: v.Value, isDel: true})...)
+		}
+	}
+	e.mu.Unlock()
+	deliver(watchers)
+}
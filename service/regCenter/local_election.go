@@ -0,0 +1,82 @@
+package regCenter
+
+import (
+	"context"
+	"sync"
+)
+
+// LocalElection the in-memory leader election for the candidates in one process
+type LocalElection struct {
+	mu      sync.Mutex
+	leaders map[string]*localLeadership
+	changed map[string]chan struct{}
+}
+
+func NewLocalElection() *LocalElection {
+	return &LocalElection{
+		leaders: make(map[string]*localLeadership),
+		changed: make(map[string]chan struct{}),
+	}
+}
+
+func (e *LocalElection) Campaign(ctx context.Context, name, val string) (Leadership, error) {
+	for {
+		e.mu.Lock()
+		if _, ok := e.leaders[name]; !ok {
+			l := &localLeadership{election: e, name: name, val: val, lost: make(chan struct{})}
+			e.leaders[name] = l
+			e.mu.Unlock()
+			return l, nil
+		}
+		changed, ok := e.changed[name]
+		if !ok {
+			changed = make(chan struct{})
+			e.changed[name] = changed
+		}
+		e.mu.Unlock()
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-changed:
+		}
+	}
+}
+
+func (e *LocalElection) Leader(_ context.Context, name string) (string, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if l, ok := e.leaders[name]; ok {
+		return l.val, nil
+	}
+	return "", nil
+}
+
+func (e *LocalElection) resign(l *localLeadership) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.leaders[l.name] != l {
+		return
+	}
+	delete(e.leaders, l.name)
+	close(l.lost)
+	if changed, ok := e.changed[l.name]; ok {
+		close(changed)
+		delete(e.changed, l.name)
+	}
+}
+
+type localLeadership struct {
+	election *LocalElection
+	name     string
+	val      string
+	lost     chan struct{}
+}
+
+func (l *localLeadership) Lost() <-chan struct{} {
+	return l.lost
+}
+
+func (l *localLeadership) Resign(_ context.Context) error {
+	l.election.resign(l)
+	return nil
+}
@@ -0,0 +1,64 @@
+package regCenter
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestLocalElection(t *testing.T) {
+	e := NewLocalElection()
+	ctx := context.Background()
+	l1, err := e.Campaign(ctx, "job", "a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if val, _ := e.Leader(ctx, "job"); val != "a" {
+		t.Errorf("leader a need, but %s", val)
+	}
+
+	elected := make(chan Leadership, 1)
+	go func() {
+		l2, err1 := e.Campaign(ctx, "job", "b")
+		if err1 == nil {
+			elected <- l2
+		}
+	}()
+	select {
+	case <-elected:
+		t.Fatal("second candidate waits need, but elected")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	_ = l1.Resign(ctx)
+	select {
+	case <-l1.Lost():
+	default:
+		t.Error("resigned leadership lost need, but not")
+	}
+	var l2 Leadership
+	select {
+	case l2 = <-elected:
+	case <-time.After(time.Second):
+		t.Fatal("second candidate elected need, but timeout")
+	}
+	if val, _ := e.Leader(ctx, "job"); val != "b" {
+		t.Errorf("leader b need, but %s", val)
+	}
+	_ = l2.Resign(ctx)
+	if val, _ := e.Leader(ctx, "job"); val != "" {
+		t.Errorf("no leader need, but %s", val)
+	}
+}
+
+func TestLocalElectionCanceled(t *testing.T) {
+	e := NewLocalElection()
+	if _, err := e.Campaign(context.Background(), "job", "a"); err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := e.Campaign(ctx, "job", "b"); err == nil {
+		t.Error("canceled campaign error need, but nil")
+	}
+}
@@ -0,0 +1,158 @@
+package regCenter
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// fakeClock set the clock of the register, advance it to expire the leases
+func fakeClock(r *LocalRegister) func(d time.Duration) {
+	now := time.Now()
+	r.mu.Lock()
+	r.now = func() time.Time { return now }
+	r.mu.Unlock()
+	return func(d time.Duration) {
+		r.mu.Lock()
+		now = now.Add(d)
+		r.mu.Unlock()
+	}
+}
+
+func TestLocalRegisterConcurrent(t *testing.T) {
+	r, _ := NewLocalRegister(context.Background())
+	defer r.Release()
+	var events int64
+	ctx, cancel := context.WithCancel(context.Background())
+	_ = r.Watch(ctx, "dev/", func(key string, val string, isDel bool) {
+		atomic.AddInt64(&events, 1)
+	})
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			key := "dev/rpc/" + strconv.Itoa(i)
+			for j := 0; j < 50; j++ {
+				_ = r.Register(context.Background(), key, strconv.Itoa(j), 1)
+				_, _ = r.LastPrefixedIndex(context.Background(), "dev/", func(string) int { return j })
+				_ = r.Unregister(context.Background(), key)
+			}
+		}(i)
+	}
+	wg.Wait()
+	if n := atomic.LoadInt64(&events); n != 1000 {
+		t.Errorf("1000 events need, but %d", n)
+	}
+	cancel()
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		r.mu.Lock()
+		n := len(r.watchers)
+		r.mu.Unlock()
+		if n == 0 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("watcher removed need, but timeout")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	_ = r.Register(context.Background(), "dev/rpc/x", "x", 0)
+	if n := atomic.LoadInt64(&events); n != 1000 {
+		t.Errorf("unsubscribed watcher not notified need, but %d events", n)
+	}
+}
+
+func TestLocalRegisterTtl(t *testing.T) {
+	r, _ := NewLocalRegister(context.Background())
+	defer r.Release()
+	advance := fakeClock(r)
+	var deleted []string
+	_ = r.Watch(context.Background(), "dev/", func(key string, val string, isDel bool) {
+		if isDel {
+			deleted = append(deleted, key)
+		}
+	})
+	keepCtx, stopKeep := context.WithCancel(context.Background())
+	_ = r.Register(keepCtx, "dev/kept", "1", 1)
+	_ = r.Register(context.Background(), "dev/background", "2", 1)
+	r.Put("dev/lease", "3", 1)
+	r.Put("dev/refreshed", "4", 1)
+	_ = r.Register(context.Background(), "dev/forever", "5", 0)
+
+	advance(700 * time.Millisecond)
+	if err := r.KeepAlive("dev/refreshed"); err != nil {
+		t.Fatal(err)
+	}
+	advance(700 * time.Millisecond)
+	r.sweep()
+	if len(deleted) != 1 || deleted[0] != "dev/lease" {
+		t.Errorf("dev/lease expired need, but %v", deleted)
+	}
+
+	advance(time.Second)
+	r.sweep()
+	if len(deleted) != 2 || deleted[1] != "dev/refreshed" {
+		t.Errorf("dev/refreshed expired need, but %v", deleted)
+	}
+
+	stopKeep()
+	advance(2 * time.Second)
+	r.sweep()
+	if len(deleted) != 3 || deleted[2] != "dev/kept" {
+		t.Errorf("dev/kept expired after keepalive stopped need, but %v", deleted)
+	}
+
+	if err := r.KeepAlive("dev/lease"); err == nil {
+		t.Error("expired key error need, but nil")
+	}
+	// a never done ctx keeps the lease until unregistered
+	kvs, _ := r.List(context.Background(), "dev/")
+	if kvs["dev/background"] != "2" || kvs["dev/forever"] != "5" {
+		t.Errorf("background and forever keys kept need, but %v", kvs)
+	}
+}
+
+func TestLocalWatchOrdered(t *testing.T) {
+	r, _ := NewLocalRegister(context.Background())
+	defer r.Release()
+	const key = "dev/rpc/a"
+	var mu sync.Mutex
+	last := make(map[int]string)
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				_ = r.Register(context.Background(), key, strconv.Itoa(i*100+j), 0)
+			}
+		}(i)
+	}
+	// the watchers started during the changes replay the snapshot in order with them
+	for i := 0; i < 4; i++ {
+		i := i
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_ = r.Watch(context.Background(), "dev/", func(k string, val string, isDel bool) {
+				mu.Lock()
+				last[i] = val
+				mu.Unlock()
+			})
+		}()
+	}
+	wg.Wait()
+	kvs, _ := r.List(context.Background(), "dev/")
+	mu.Lock()
+	defer mu.Unlock()
+	for i := 0; i < 4; i++ {
+		if last[i] != kvs[key] {
+			t.Errorf("watcher %d last value %s need, but %s", i, kvs[key], last[i])
+		}
+	}
+}
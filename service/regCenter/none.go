@@ -21,3 +21,6 @@ func (s *None) Watch(ctx context.Context, keyPrefix string, handler func(key str
 func (s *None) LastPrefixedIndex(ctx context.Context, keyPrefix string, indexParser func(key string) int) (int, error) {
 	return 0, nil
 }
+func (s *None) List(ctx context.Context, keyPrefix string) (map[string]string, error) {
+	return map[string]string{}, nil
+}
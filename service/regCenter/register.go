@@ -3,6 +3,7 @@ package regCenter
 import (
 	"context"
 	"github.com/obnahsgnaw/application/regtype"
+	"go.uber.org/zap"
 	"strings"
 )
 
@@ -13,6 +14,21 @@ type Register interface {
 	LastPrefixedIndex(ctx context.Context, keyPrefix string, indexParser func(key string) int) (int, error)
 }
 
+// Lister a register center lists the prefixed entries
+type Lister interface {
+	List(ctx context.Context, keyPrefix string) (map[string]string, error)
+}
+
+// Endpointer a register center reports its backend endpoints
+type Endpointer interface {
+	Endpoints() []string
+}
+
+// LoggerSetter a register center logs its background failures
+type LoggerSetter interface {
+	SetLogger(l *zap.Logger)
+}
+
 type ServerInfo struct {
 	Id      string
 	Name    string
@@ -30,22 +46,53 @@ type RegInfo struct {
 	Ttl        int64
 	KeyPreGen  RegKeyPrefixGenerator
 	Values     map[string]string // 多个值设置这个
+	Instance   *ServiceInstance  // 结构化实例, 以json写入单个key
 }
 
 func (r *RegInfo) Prefix() string {
 	if r.KeyPreGen == nil {
-		r.KeyPreGen = DefaultRegKeyPrefixGenerator()
+		return DefaultRegKeyPrefixGenerator()(r)
 	}
 	return r.KeyPreGen(r)
 }
 func (r *RegInfo) Key() string {
-	prefix := r.Prefix()
-	return strings.TrimPrefix(strings.Join([]string{prefix, r.ServerInfo.Id, r.Host}, "/"), "/")
+	return r.key(r.Host)
+}
+
+func (r *RegInfo) key(host string) string {
+	return strings.TrimPrefix(strings.Join([]string{r.Prefix(), r.ServerInfo.Id, host}, "/"), "/")
 }
 
+// Kvs return the register center entries, an instance failed to encode is left out, see EncodeKvs
 func (r *RegInfo) Kvs() map[string]string {
+	kvs, err := r.EncodeKvs()
+	if err != nil {
+		return make(map[string]string)
+	}
+	return kvs
+}
+
+// EncodeKvs return the register center entries, the instance id and address default to the server id and host, the info is not modified
+func (r *RegInfo) EncodeKvs() (map[string]string, error) {
 	kvs := make(map[string]string)
-	if r.Values != nil && len(r.Values) > 0 {
+	if r.Instance != nil {
+		instance := *r.Instance
+		if instance.Id == "" {
+			instance.Id = r.ServerInfo.Id
+		}
+		if instance.Address == "" {
+			instance.Address = r.Host
+		}
+		host := r.Host
+		if host == "" {
+			host = instance.Address
+		}
+		v, err := instance.Encode()
+		if err != nil {
+			return nil, err
+		}
+		kvs[r.key(host)] = v
+	} else if r.Values != nil && len(r.Values) > 0 {
 		key := r.Key()
 		for k, v := range r.Values {
 			kvs[key+"/"+k] = v
@@ -53,7 +100,7 @@ func (r *RegInfo) Kvs() map[string]string {
 	} else {
 		kvs[r.Key()] = r.Val
 	}
-	return kvs
+	return kvs, nil
 }
 
 // RegKeyPrefixGenerator register key prefix generator  :  prefix/server-id/host
@@ -0,0 +1,67 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"go.uber.org/multierr"
+	"go.uber.org/zap"
+	"sync"
+)
+
+// GracefulReleaser a callback server can drain and release within the context deadline
+type GracefulReleaser interface {
+	GracefulRelease(ctx context.Context) error
+}
+
+func (app *Application) releaseLevel(ctx context.Context, level []Server) error {
+	errs := make([]error, len(level))
+	var wg sync.WaitGroup
+	for i, s := range level {
+		wg.Add(1)
+		go func(i int, s Server) {
+			defer wg.Done()
+			errs[i] = app.releaseServer(ctx, s)
+		}(i, s)
+	}
+	wg.Wait()
+	return multierr.Combine(errs...)
+}
+
+func (app *Application) releaseServer(ctx context.Context, s Server) error {
+	app.markStopping(s, true)
+	ctx, cancel := context.WithTimeout(ctx, app.serverReleaseTimeout)
+	defer cancel()
+	done := make(chan error, 1)
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				done <- fmt.Errorf("release panic: %v", r)
+			}
+		}()
+		done <- app.stopServer(ctx, s)
+	}()
+	select {
+	case err := <-done:
+		if err != nil {
+			return newServerError(s, "release", err)
+		}
+		return nil
+	case <-ctx.Done():
+		return newServerError(s, "release", errors.New("release timeout, "+ctx.Err().Error()))
+	}
+}
+
+func (app *Application) logReleaseErrors(err error) {
+	if err == nil {
+		return
+	}
+	for _, e := range multierr.Errors(err) {
+		var se *ServerError
+		if errors.As(e, &se) {
+			app.logger.Error(app.prefixedMsg(e.Error()), zap.String("server_id", se.ID))
+		} else {
+			app.logger.Error(app.prefixedMsg(e.Error()))
+		}
+	}
+}
@@ -0,0 +1,107 @@
+package application_test
+
+import (
+	"errors"
+	"github.com/obnahsgnaw/application"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestReleaseTimeout(t *testing.T) {
+	tests := []struct {
+		name     string
+		delay    time.Duration
+		timedOut bool
+	}{
+		{name: "within deadline", delay: 0},
+		{name: "hung server", delay: time.Minute, timedOut: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := &recorder{}
+			app := newTestApp(t, application.ServerReleaseTimeout(50*time.Millisecond))
+			slow := newTestServer("slow", rec)
+			slow.stopDelay = tt.delay
+			fast := newTestServer("fast", rec)
+			mustAdd(t, app, slow, fast)
+			if err := app.Run(); err != nil {
+				t.Fatal(err)
+			}
+			start := time.Now()
+			err := app.Release()
+			if elapsed := time.Since(start); elapsed > time.Second {
+				t.Errorf("release bounded by the server timeout need, but took %s", elapsed)
+			}
+			if rec.index("stop:fast") < 0 {
+				t.Errorf("fast server released need, but %v", rec.list())
+			}
+			list := application.ServerErrors(err)
+			if !tt.timedOut {
+				if err != nil {
+					t.Errorf("nil error need, but %v", err)
+				}
+				return
+			}
+			if len(list) != 1 || list[0].ID != "slow" || list[0].Op != "release" || !strings.Contains(list[0].Error(), "timeout") {
+				t.Errorf("slow server release timeout error need, but %v", err)
+			}
+		})
+	}
+}
+
+func TestReleaseWithoutRun(t *testing.T) {
+	rec := &recorder{}
+	app := newTestApp(t)
+	mustAdd(t, app, newTestServer("a", rec), newTestServer("b", rec, "a"))
+	if err := app.Release(); err != nil {
+		t.Fatal(err)
+	}
+	if len(rec.list()) != 0 {
+		t.Errorf("not started servers not released need, but %v", rec.list())
+	}
+}
+
+func TestReleaseTwice(t *testing.T) {
+	rec := &recorder{}
+	app := newTestApp(t)
+	mustAdd(t, app, newTestServer("a", rec))
+	if err := app.Run(); err != nil {
+		t.Fatal(err)
+	}
+	_ = app.Release()
+	if err := app.Release(); err != nil {
+		t.Fatal(err)
+	}
+	if rec.count("stop:a") != 1 {
+		t.Errorf("released once need, but %v", rec.list())
+	}
+}
+
+func TestReleaseCancelsContext(t *testing.T) {
+	tests := []struct {
+		name     string
+		startErr error
+	}{
+		{name: "released after run"},
+		{name: "run failed", startErr: errors.New("start refused")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := newTestApp(t)
+			s := newTestServer("a", &recorder{})
+			s.startErr = tt.startErr
+			mustAdd(t, app, s)
+			if err := app.Run(); (err != nil) != (tt.startErr != nil) {
+				t.Fatalf("run error %v need, but %v", tt.startErr, err)
+			}
+			if tt.startErr != nil && app.Context().Err() == nil {
+				t.Error("context canceled by the run failure need, but not")
+			}
+			_ = app.Release()
+			if app.Context().Err() == nil {
+				t.Error("context canceled by the release need, but not")
+			}
+		})
+	}
+}
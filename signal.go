@@ -0,0 +1,87 @@
+package application
+
+import (
+	"bytes"
+	"context"
+	"github.com/obnahsgnaw/application/pkg/signals"
+	"go.uber.org/multierr"
+	"go.uber.org/zap"
+	"os"
+	"runtime"
+	"runtime/pprof"
+)
+
+// ReloadTopic the event topic fired on reload
+const ReloadTopic = "app.reload"
+
+// AddReload add a reload func called on the reload signal, such as reload the config
+func (app *Application) AddReload(r func() error) {
+	if r != nil {
+		app.reloads = append(app.reloads, r)
+	}
+}
+
+// Reload reopen the log files, read the config sources again, call the reloads of the application and the sub-applications,
+// then fire the ReloadTopic event, the reloads are serialized
+func (app *Application) Reload() (err error) {
+	app.reloadMu.Lock()
+	defer app.reloadMu.Unlock()
+	app.logger.Info(app.prefixedMsg("reloading..."))
+	if reopenErr := app.logCnf.ReopenFiles(); reopenErr != nil {
+		err = multierr.Append(err, app.error("log files reopen failed", reopenErr))
+	}
+	err = multierr.Append(err, app.reloadConfig())
+	for _, r := range app.reloads {
+		err = multierr.Append(err, r())
+	}
+	for _, sub := range app.Children() {
+		if sub.State() != Stopped {
+			err = multierr.Append(err, sub.Reload())
+		}
+	}
+	if err != nil {
+		app.logger.Error(app.prefixedMsg("reload failed"), zap.Error(err))
+	} else {
+		app.logger.Info(app.prefixedMsg("reloaded"))
+	}
+	_ = app.logger.Sync()
+	app.event.NewEvent(ReloadTopic, []interface{}{app}).Fire()
+	return
+}
+
+// Dump log the goroutine stacks and the server status, the health checks are bounded by the health check timeout
+func (app *Application) Dump() {
+	buf := &bytes.Buffer{}
+	if p := pprof.Lookup("goroutine"); p != nil {
+		_ = p.WriteTo(buf, 2)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), app.healthCheckTimeout)
+	defer cancel()
+	app.logger.Info(app.prefixedMsg("dump"),
+		zap.String("state", app.State().String()),
+		zap.Int("goroutines", runtime.NumGoroutine()),
+		zap.Any("health", app.Health(ctx)),
+		zap.Errors("errors", app.Errors()),
+		zap.String("stacks", buf.String()),
+	)
+}
+
+// newSignalRouter route the reload, dump and shutdown signals, a second shutdown signal forces exit
+func (app *Application) newSignalRouter() *signals.Router {
+	r := signals.NewRouter()
+	r.Handle(func(os.Signal) {
+		_ = app.Reload()
+	}, signals.ReloadSignals()...)
+	r.Handle(func(os.Signal) {
+		app.Dump()
+	}, signals.DumpSignals()...)
+	r.OnShutdown(func(s os.Signal) {
+		app.logger.Info(app.prefixedMsg("shutdown signal received: ", s.String()))
+	})
+	r.OnForceExit(func(s os.Signal) {
+		app.logger.Warn(app.prefixedMsg("shutdown signal received again, force exit: ", s.String()))
+		_ = app.logger.Sync()
+		os.Exit(1)
+	})
+	return r
+}
@@ -0,0 +1,51 @@
+package application_test
+
+import (
+	"github.com/obnahsgnaw/application"
+	"github.com/obnahsgnaw/application/pkg/config"
+	"github.com/obnahsgnaw/application/service/event"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestReloadConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.json")
+	if err := os.WriteFile(path, []byte(`{"debug":false}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	app := newTestApp(t, application.WatchConfig(config.NewFileSource(path, time.Hour)))
+	defer app.Release()
+	changes := make(chan *config.Change, 1)
+	app.Event().Register(application.ConfigChangedTopic, func(e *event.Event) {
+		changes <- e.Data[0].(*config.Change)
+	})
+	reloaded := make(chan struct{}, 1)
+	app.AddReload(func() error {
+		reloaded <- struct{}{}
+		return nil
+	})
+	if err := app.Run(); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(`{"debug":true}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := app.Reload(); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case c := <-changes:
+		if string(c.Data) != `{"debug":true}` {
+			t.Errorf("re-read content need, but %s", c.Data)
+		}
+	default:
+		t.Error("config change on reload need, but none")
+	}
+	select {
+	case <-reloaded:
+	default:
+		t.Error("reload func called need, but not")
+	}
+}
@@ -0,0 +1,147 @@
+package application
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/obnahsgnaw/application/service/regCenter"
+	"go.uber.org/zap"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// StartupReport the structured startup summary of an application and its sub-applications
+type StartupReport struct {
+	App      string           `json:"app"`
+	Cluster  ClusterReport    `json:"cluster"`
+	Debug    bool             `json:"debug"`
+	Register RegisterReport   `json:"register"`
+	Log      LogReport        `json:"log"`
+	Servers  []ServerReport   `json:"servers"`
+	Children []*StartupReport `json:"children,omitempty"`
+}
+
+// ClusterReport the cluster summary
+type ClusterReport struct {
+	Id   string `json:"id"`
+	Name string `json:"name"`
+	App  string `json:"app"`
+}
+
+// ServerReport the added server summary
+type ServerReport struct {
+	Id      string `json:"id"`
+	Name    string `json:"name"`
+	Type    string `json:"type"`
+	EndType string `json:"end_type"`
+}
+
+// RegisterReport the register center summary
+type RegisterReport struct {
+	Backend   string   `json:"backend"`
+	Endpoints []string `json:"endpoints,omitempty"`
+	Ttl       int64    `json:"ttl"`
+}
+
+// LogReport the logger summary with the current levels, an empty dir means logging to std
+type LogReport struct {
+	Dir        string `json:"dir"`
+	Level      string `json:"level"`
+	TraceLevel string `json:"trace_level"`
+	Format     string `json:"format"`
+}
+
+// StartupReport return the startup summary
+func (app *Application) StartupReport() *StartupReport {
+	report := &StartupReport{
+		App: app.name,
+		Cluster: ClusterReport{
+			Id:   app.cluster.Id(),
+			Name: app.cluster.Name(),
+			App:  app.name,
+		},
+		Debug:    app.debugger.Debug(),
+		Register: app.registerReport(),
+		Log: LogReport{
+			Dir:        app.logCnf.GetFullDir(),
+			Level:      liveLevel(app.logCnf.GetLevel(), app.logCnf.GetLevelString()),
+			TraceLevel: liveLevel(app.logCnf.GetTraceLevel(), app.logCnf.GetTraceLevelString()),
+			Format:     app.logCnf.GetFormat(),
+		},
+		Servers: make([]ServerReport, 0),
+	}
+	for _, s := range app.serverList() {
+		report.Servers = append(report.Servers, ServerReport{
+			Id:      s.ID(),
+			Name:    s.Name(),
+			Type:    s.Type().String(),
+			EndType: s.EndType().String(),
+		})
+	}
+	sort.Slice(report.Servers, func(i, j int) bool {
+		return report.Servers[i].Type+report.Servers[i].EndType+report.Servers[i].Id < report.Servers[j].Type+report.Servers[j].EndType+report.Servers[j].Id
+	})
+	for _, sub := range app.Children() {
+		subReport := sub.StartupReport()
+		if !sub.cusRegister {
+			subReport.Register = report.Register
+		}
+		if !sub.logCus {
+			subReport.Log = report.Log
+		}
+		report.Children = append(report.Children, subReport)
+	}
+	return report
+}
+
+// liveLevel return the current level, the configured one if the level is not initialized
+func liveLevel(l zap.AtomicLevel, configured string) string {
+	if l == (zap.AtomicLevel{}) {
+		return configured
+	}
+	return l.Level().String()
+}
+
+func (app *Application) registerReport() RegisterReport {
+	r := RegisterReport{Ttl: app.regTtl}
+	switch app.register.(type) {
+	case nil, *regCenter.None:
+		r.Backend = "none"
+	case *regCenter.LocalRegister:
+		r.Backend = "local"
+	case *regCenter.EtcdRegister:
+		r.Backend = "etcd"
+	case *regCenter.FileRegister:
+		r.Backend = "file"
+	default:
+		r.Backend = fmt.Sprintf("%T", app.register)
+	}
+	if e, ok := app.register.(regCenter.Endpointer); ok {
+		r.Endpoints = e.Endpoints()
+	}
+	return r
+}
+
+// displayConfig log the startup summary, and write it to the report file if set
+func (app *Application) displayConfig() error {
+	report := app.StartupReport()
+	app.logger.Info(app.prefixedMsg("startup summary"),
+		zap.Any("cluster", report.Cluster),
+		zap.Bool("debug", report.Debug),
+		zap.Any("register", report.Register),
+		zap.Any("log", report.Log),
+		zap.Any("servers", report.Servers),
+		zap.Any("children", report.Children),
+	)
+	if app.reportFile == "" {
+		return nil
+	}
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err = os.MkdirAll(filepath.Dir(app.reportFile), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(app.reportFile, data, 0644)
+}
@@ -0,0 +1,29 @@
+package application_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestStartupReportLevels(t *testing.T) {
+	app := newTestApp(t)
+	defer app.Release()
+	mustAdd(t, app, newTestServer("api", &recorder{}))
+	report := app.StartupReport()
+	if report.Log.Level == "" || len(report.Servers) != 1 || report.Servers[0].Id != "api" {
+		t.Errorf("configured level and the server need before run, but %+v", report)
+	}
+	if err := app.Run(); err != nil {
+		t.Fatal(err)
+	}
+	rec := httptest.NewRecorder()
+	app.AdminHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodPut, "/log-level", strings.NewReader(`{"level":"warn"}`)))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("level changed need, but %d %s", rec.Code, rec.Body)
+	}
+	if level := app.StartupReport().Log.Level; level != "warn" {
+		t.Errorf("live level warn need, but %s", level)
+	}
+}
@@ -0,0 +1,35 @@
+package application
+
+import (
+	"context"
+	"github.com/obnahsgnaw/application/pkg/tracing"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Traced a server uses the tracer provider of the application, it is set before the server started
+type Traced interface {
+	SetTracerProvider(tp trace.TracerProvider)
+}
+
+// TracerProvider return the tracer provider, default the global provider
+func (app *Application) TracerProvider() trace.TracerProvider {
+	if app.tracerProvider != nil {
+		return app.tracerProvider
+	}
+	return otel.GetTracerProvider()
+}
+
+// Tracer return the tracer named by the application
+func (app *Application) Tracer(opts ...trace.TracerOption) trace.Tracer {
+	return app.TracerProvider().Tracer(app.name, opts...)
+}
+
+// traceServer set the tracer provider to the server, return the context carries the provider
+func (app *Application) traceServer(ctx context.Context, s Server) context.Context {
+	tp := app.TracerProvider()
+	if t, ok := s.(Traced); ok {
+		t.SetTracerProvider(tp)
+	}
+	return tracing.ContextWithProvider(ctx, tp)
+}
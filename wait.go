@@ -0,0 +1,54 @@
+package application
+
+import (
+	"github.com/obnahsgnaw/application/pkg/utils"
+	"os"
+)
+
+// StopCause the cause of Wait returned
+type StopCause string
+
+const (
+	StopBySignal        StopCause = "signal"
+	StopByContext       StopCause = "context"
+	StopByServerFailure StopCause = "server-failure"
+	StopByCall          StopCause = "stop"
+)
+
+// StopReason why Wait returned, Signal is set for StopBySignal, Err for StopByContext and StopByServerFailure
+type StopReason struct {
+	Cause  StopCause
+	Signal os.Signal
+	Err    error
+}
+
+func (r *StopReason) String() string {
+	switch {
+	case r.Signal != nil:
+		return utils.ToStr(string(r.Cause), ": ", r.Signal.String())
+	case r.Err != nil:
+		return utils.ToStr(string(r.Cause), ": ", r.Err.Error())
+	default:
+		return string(r.Cause)
+	}
+}
+
+// Stop make Wait return
+func (app *Application) Stop() {
+	select {
+	case app.stopCh <- struct{}{}:
+	default:
+	}
+}
+
+// fatal a server failure which is not recovered makes Wait return, a sub-application reports it to the parent, which stops only the child
+func (app *Application) fatal(err error) {
+	if app.parent != nil {
+		go app.parent.childFatal(app.name, err)
+		return
+	}
+	select {
+	case app.fatalCh <- err:
+	default:
+	}
+}
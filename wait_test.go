@@ -0,0 +1,77 @@
+package application_test
+
+import (
+	"context"
+	"errors"
+	"github.com/obnahsgnaw/application"
+	"testing"
+	"time"
+)
+
+// waitReason run Wait after the trigger, fail if it does not return
+func waitReason(t *testing.T, app *application.Application, trigger func()) *application.StopReason {
+	t.Helper()
+	reasons := make(chan *application.StopReason, 1)
+	go func() {
+		reasons <- app.Wait()
+	}()
+	trigger()
+	select {
+	case r := <-reasons:
+		return r
+	case <-time.After(2 * time.Second):
+		t.Fatal("wait returned need, but timeout")
+		return nil
+	}
+}
+
+func TestWait(t *testing.T) {
+	failure := errors.New("crashed")
+	tests := []struct {
+		name    string
+		trigger func(app *application.Application, s *testServer, cancel context.CancelFunc)
+		cause   application.StopCause
+		err     error
+	}{
+		{
+			name:    "stop",
+			trigger: func(app *application.Application, _ *testServer, _ context.CancelFunc) { app.Stop() },
+			cause:   application.StopByCall,
+		},
+		{
+			name:    "context",
+			trigger: func(_ *application.Application, _ *testServer, cancel context.CancelFunc) { cancel() },
+			cause:   application.StopByContext,
+			err:     context.Canceled,
+		},
+		{
+			name:    "server failure",
+			trigger: func(_ *application.Application, s *testServer, _ context.CancelFunc) { s.exited <- failure },
+			cause:   application.StopByServerFailure,
+			err:     failure,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			app := newTestApp(t, application.Context(ctx))
+			defer app.Release()
+			s := newTestServer("api", &recorder{})
+			mustAdd(t, app, s)
+			if err := app.Run(); err != nil {
+				t.Fatal(err)
+			}
+			r := waitReason(t, app, func() { tt.trigger(app, s, cancel) })
+			if r.Cause != tt.cause {
+				t.Errorf("cause %s need, but %s", tt.cause, r.Cause)
+			}
+			if tt.err != nil && !errors.Is(r.Err, tt.err) {
+				t.Errorf("error %v need, but %v", tt.err, r.Err)
+			}
+			if app.State() != application.Draining {
+				t.Errorf("draining need, but %s", app.State())
+			}
+		})
+	}
+}
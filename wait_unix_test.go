@@ -0,0 +1,27 @@
+//go:build !windows
+
+package application_test
+
+import (
+	"github.com/obnahsgnaw/application"
+	"os"
+	"syscall"
+	"testing"
+)
+
+func TestWaitSignal(t *testing.T) {
+	app := application.New("test")
+	defer app.Release()
+	if err := app.Run(); err != nil {
+		t.Fatal(err)
+	}
+	r := waitReason(t, app, func() {
+		waitFor(t, "signals listened", func() bool {
+			return application.SignalsListening(app)
+		})
+		_ = syscall.Kill(os.Getpid(), syscall.SIGTERM)
+	})
+	if r.Cause != application.StopBySignal || r.Signal != syscall.SIGTERM {
+		t.Errorf("SIGTERM stop need, but %s", r)
+	}
+}